@@ -0,0 +1,108 @@
+// Package apperrors is the catalog of errors handlers report to clients.
+// Handlers attach them with c.Error and middlewares.ErrorHandler renders them.
+package apperrors
+
+import (
+	"net/http"
+
+	"github.com/VisarutJDev/social-media-api/models"
+)
+
+// Stable codes reported in models.Response.Code
+const (
+	CodeBadRequest           = "bad_request"
+	CodeInvalidID            = "invalid_id"
+	CodeValidation           = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeRateLimited          = "rate_limited"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodePreconditionFailed   = "precondition_failed"
+	CodePreconditionRequired = "precondition_required"
+	CodeInternal             = "internal_error"
+)
+
+// Error is an error with the status and code it is reported with
+type Error struct {
+	Status  int
+	Code    string
+	Message string              // Shown to clients
+	Fields  []models.FieldError // Fields that failed validation
+	Err     error               // Underlying cause, logged but never shown to clients
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Response renders e as the body sent to clients
+func (e *Error) Response() models.Response {
+	return models.Response{Error: e.Message, Code: e.Code, Fields: e.Fields}
+}
+
+// BadRequest reports a request that could not be read, such as malformed JSON or query parameters
+func BadRequest(message string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeBadRequest, Message: message}
+}
+
+// InvalidID reports an id that is not a valid ObjectID; name says what it identifies
+func InvalidID(name string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeInvalidID, Message: "Invalid " + name + " id"}
+}
+
+// Validation reports a well-formed request whose fields break the validation rules
+func Validation(fields ...models.FieldError) *Error {
+	return &Error{Status: http.StatusUnprocessableEntity, Code: CodeValidation, Message: "Validation failed", Fields: fields}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Status: http.StatusUnauthorized, Code: CodeUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Status: http.StatusForbidden, Code: CodeForbidden, Message: message}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: message}
+}
+
+// Conflict reports a request that clashes with the current state, such as a taken username
+func Conflict(message string) *Error {
+	return &Error{Status: http.StatusConflict, Code: CodeConflict, Message: message}
+}
+
+// PreconditionFailed reports a conditional write whose If-Match or version no longer matches
+func PreconditionFailed(message string) *Error {
+	return &Error{Status: http.StatusPreconditionFailed, Code: CodePreconditionFailed, Message: message}
+}
+
+// PreconditionRequired reports a write that must say which version it expects
+func PreconditionRequired(message string) *Error {
+	return &Error{Status: http.StatusPreconditionRequired, Code: CodePreconditionRequired, Message: message}
+}
+
+// UnsupportedMediaType reports a body sent with a Content-Type the endpoint does not read
+func UnsupportedMediaType(message string) *Error {
+	return &Error{Status: http.StatusUnsupportedMediaType, Code: CodeUnsupportedMediaType, Message: message}
+}
+
+// TooManyRequests reports a caller that is rate limited or locked out. The
+// caller sets the Retry-After header.
+func TooManyRequests(message string) *Error {
+	return &Error{Status: http.StatusTooManyRequests, Code: CodeRateLimited, Message: message}
+}
+
+// Internal hides err from clients behind a generic message
+func Internal(err error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "Internal server error", Err: err}
+}
@@ -0,0 +1,11 @@
+package auth
+
+import "github.com/golang-jwt/jwt/v4"
+
+// Claims are carried by the access tokens this API issues
+type Claims struct {
+	Username string `json:"username"`
+	Role     string `json:"role,omitempty"` // Role when the token was issued; authorization uses the stored role
+	FamilyID string `json:"fid,omitempty"`  // Refresh token family the access token was issued with
+	jwt.StandardClaims
+}
@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/VisarutJDev/social-media-api/config"
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Key is one key tokens are signed or verified with
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{} // nil for keys kept only to verify older tokens
+	verifyKey interface{}
+}
+
+// KeySet signs new tokens with one key and verifies tokens with any key it
+// holds, so a key can be rotated out without invalidating issued tokens
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// NewHMACKeySet signs and verifies HS256 tokens with a shared secret and no kid
+func NewHMACKeySet(secret []byte) *KeySet {
+	key := &Key{Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+	return &KeySet{signing: key, keys: map[string]*Key{"": key}}
+}
+
+// NewKeySet builds a key set from keys, signing with the key whose ID is signingKeyID
+func NewKeySet(signingKeyID string, keys ...*Key) (*KeySet, error) {
+	ks := &KeySet{keys: map[string]*Key{}}
+	for _, key := range keys {
+		if _, ok := ks.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate jwt key id %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+	ks.signing = ks.keys[signingKeyID]
+	if ks.signing == nil {
+		return nil, fmt.Errorf("jwt signing key %q is not configured", signingKeyID)
+	}
+	if ks.signing.signKey == nil {
+		return nil, fmt.Errorf("jwt signing key %q has no private key", signingKeyID)
+	}
+	return ks, nil
+}
+
+// NewRSAKey wraps an RS256 key pair; private may be nil for a verification-only key
+func NewRSAKey(id string, private *rsa.PrivateKey, public *rsa.PublicKey) *Key {
+	key := &Key{ID: id, Method: jwt.SigningMethodRS256, verifyKey: public}
+	if private != nil {
+		key.signKey = private
+		key.verifyKey = &private.PublicKey
+	}
+	return key
+}
+
+// NewEd25519Key wraps an EdDSA key pair; private may be nil for a verification-only key
+func NewEd25519Key(id string, private ed25519.PrivateKey, public ed25519.PublicKey) *Key {
+	key := &Key{ID: id, Method: jwt.SigningMethodEdDSA, verifyKey: public}
+	if private != nil {
+		key.signKey = private
+		key.verifyKey = private.Public()
+	}
+	return key
+}
+
+// LoadKeySet builds the key set described by cfg. Without any JwtKeys it
+// falls back to HS256 with JwtKey.
+func LoadKeySet(cfg config.Configuration) (*KeySet, error) {
+	if len(cfg.JwtKeys) == 0 {
+		if cfg.JwtKey == "" {
+			return nil, errors.New("either jwtKey or jwtKeys must be configured")
+		}
+		return NewHMACKeySet([]byte(cfg.JwtKey)), nil
+	}
+
+	keys := make([]*Key, 0, len(cfg.JwtKeys))
+	for _, keyConfig := range cfg.JwtKeys {
+		key, err := loadKey(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", keyConfig.ID, err)
+		}
+		keys = append(keys, key)
+	}
+	return NewKeySet(cfg.JwtSigningKeyID, keys...)
+}
+
+func loadKey(keyConfig config.JwtKeyConfig) (*Key, error) {
+	if keyConfig.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	var privatePEM, publicPEM []byte
+	var err error
+	if keyConfig.PrivateKeyFile != "" {
+		if privatePEM, err = os.ReadFile(keyConfig.PrivateKeyFile); err != nil {
+			return nil, err
+		}
+	}
+	if keyConfig.PublicKeyFile != "" {
+		if publicPEM, err = os.ReadFile(keyConfig.PublicKeyFile); err != nil {
+			return nil, err
+		}
+	}
+	if privatePEM == nil && publicPEM == nil {
+		return nil, errors.New("privateKeyFile or publicKeyFile is required")
+	}
+
+	switch keyConfig.Algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		if privatePEM != nil {
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			return NewRSAKey(keyConfig.ID, private, nil), nil
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+		if err != nil {
+			return nil, err
+		}
+		return NewRSAKey(keyConfig.ID, nil, public), nil
+	case jwt.SigningMethodEdDSA.Alg():
+		if privatePEM != nil {
+			private, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			return NewEd25519Key(keyConfig.ID, private.(ed25519.PrivateKey), nil), nil
+		}
+		public, err := jwt.ParseEdPublicKeyFromPEM(publicPEM)
+		if err != nil {
+			return nil, err
+		}
+		return NewEd25519Key(keyConfig.ID, nil, public.(ed25519.PublicKey)), nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", keyConfig.Algorithm)
+	}
+}
+
+// Sign signs claims with the signing key, naming it in the "kid" header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	if ks.signing.ID != "" {
+		token.Header["kid"] = ks.signing.ID
+	}
+	return token.SignedString(ks.signing.signKey)
+}
+
+// Keyfunc picks the verification key named by the token's "kid" header for jwt.ParseWithClaims
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	// Never let the token pick the algorithm, e.g. HS256 keyed with a public key
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
+
+// JWKS returns the public keys as a JSON Web Key Set. Shared HMAC secrets are never published.
+func (ks *KeySet) JWKS() models.JWKS {
+	jwks := models.JWKS{Keys: []models.JWK{}}
+	for _, key := range ks.keys {
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, models.JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, models.JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	slices.SortFunc(jwks.Keys, func(a, b models.JWK) int { return strings.Compare(a.Kid, b.Kid) })
+	return jwks
+}
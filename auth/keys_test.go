@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/config"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func signAndVerify(t *testing.T, signer *KeySet, verifier *KeySet) error {
+	claims := jwt.RegisteredClaims{
+		Subject:   "testuser",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+	tokenString, err := signer.Sign(claims)
+	assert.NoError(t, err)
+	_, err = jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, verifier.Keyfunc)
+	return err
+}
+
+func TestKeySetRotation(t *testing.T) {
+	_, oldPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	newPrivate, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	before, err := NewKeySet("old", NewEd25519Key("old", oldPrivate, nil))
+	assert.NoError(t, err)
+	// After the rotation the old key only verifies
+	after, err := NewKeySet("new",
+		NewEd25519Key("old", nil, oldPrivate.Public().(ed25519.PublicKey)),
+		NewRSAKey("new", newPrivate, nil),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, signAndVerify(t, before, after))
+	assert.NoError(t, signAndVerify(t, after, after))
+	assert.Error(t, signAndVerify(t, after, before))
+
+	jwks := after.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "new", jwks.Keys[0].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.Equal(t, "old", jwks.Keys[1].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+}
+
+func TestKeySetRejectsAlgorithmSwitch(t *testing.T) {
+	private, _ := rsa.GenerateKey(rand.Reader, 2048)
+	keys, err := NewKeySet("rsa", NewRSAKey("rsa", private, nil))
+	assert.NoError(t, err)
+
+	// An HS256 token keyed with the public key must not verify
+	publicDER, _ := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "testuser"})
+	token.Header["kid"] = "rsa"
+	tokenString, err := token.SignedString(publicDER)
+	assert.NoError(t, err)
+
+	_, err = jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, keys.Keyfunc)
+	assert.Error(t, err)
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	_, private, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(private)
+	privateFile := filepath.Join(dir, "ed25519.pem")
+	os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+
+	keys, err := LoadKeySet(config.Configuration{
+		JwtSigningKeyID: "2024-01",
+		JwtKeys: []config.JwtKeyConfig{
+			{ID: "2024-01", Algorithm: "EdDSA", PrivateKeyFile: privateFile},
+		},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, signAndVerify(t, keys, keys))
+
+	_, err = LoadKeySet(config.Configuration{
+		JwtSigningKeyID: "missing",
+		JwtKeys: []config.JwtKeyConfig{
+			{ID: "2024-01", Algorithm: "EdDSA", PrivateKeyFile: privateFile},
+		},
+	})
+	assert.Error(t, err)
+
+	keys, err = LoadKeySet(config.Configuration{JwtKey: "your_secret_key"})
+	assert.NoError(t, err)
+	assert.NoError(t, signAndVerify(t, keys, keys))
+	assert.Empty(t, keys.JWKS().Keys)
+}
@@ -2,31 +2,392 @@ package config
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var Config Configuration
 
+// Profiles select one of the config_<profile>.json files
+const (
+	ProfileLocal      = "local"
+	ProfileProduction = "production"
+	ProfileUnittest   = "unittest"
+)
+
+var Profiles = []string{ProfileLocal, ProfileProduction, ProfileUnittest}
+
+// EnvPrefix starts the environment variables that override configuration fields,
+// e.g. APP_MONGO_URI for the field tagged env:"MONGO_URI"
+const EnvPrefix = "APP_"
+
+// ProfileEnv names the environment variable that selects the profile when no flag does
+const ProfileEnv = EnvPrefix + "PROFILE"
+
+// DefaultMetricsPath serves the metrics when MetricsPath is empty; MetricsOff disables them
+const (
+	DefaultMetricsPath = "/metrics"
+	MetricsOff         = "off"
+)
+
+// tracingExporters are the exporters tracing.Setup knows
+var tracingExporters = []string{"none", "stdout", "otlp"}
+
+// Migrate modes: MigrateUp applies pending migrations at startup, MigrateOff
+// leaves them to the migrate subcommand
+const (
+	MigrateUp  = "up"
+	MigrateOff = "off"
+)
+
+var migrateModes = []string{MigrateUp, MigrateOff}
+
+// logLevels and logFormats are the values logging.Setup knows
+var (
+	logLevels  = []string{"debug", "info", "warn", "error"}
+	logFormats = []string{"json", "text"}
+)
+
 type Configuration struct {
-	JwtKey   string `json:"jwtKey"`
-	MongoURI string `json:"mongoURI"`
-	Database string `json:"database"`
+	JwtKey           string         `json:"jwtKey" env:"JWT_KEY"`                     // HS256 secret, only used when JwtKeys is empty
+	JwtKeyFile       string         `json:"jwtKeyFile" env:"JWT_KEY_FILE"`            // File holding JwtKey, which then stays out of the config
+	JwtSigningKeyID  string         `json:"jwtSigningKeyId" env:"JWT_SIGNING_KEY_ID"` // kid of the key new tokens are signed with
+	JwtKeys          []JwtKeyConfig `json:"jwtKeys" env:"JWT_KEYS"`                   // JSON array when set from the environment
+	MongoURI         string         `json:"mongoURI" env:"MONGO_URI"`
+	MongoURIFile     string         `json:"mongoURIFile" env:"MONGO_URI_FILE"` // File holding MongoURI, which then stays out of the config
+	Database         string         `json:"database" env:"DATABASE"`
+	TimelineStrategy string         `json:"timelineStrategy" env:"TIMELINE_STRATEGY"` // fanout_on_read (default) or fanout_on_write
+	ReactionTypes    []string       `json:"reactionTypes" env:"REACTION_TYPES"`       // Reactions users may give posts, models.DefaultReactionTypes when empty; comma separated in the environment
+	ServerAddr       string         `json:"serverAddr" env:"SERVER_ADDR"`             // Defaults to :8080
+	ReadTimeout      Duration       `json:"readTimeout" env:"READ_TIMEOUT"`           // Defaults to 15s
+	WriteTimeout     Duration       `json:"writeTimeout" env:"WRITE_TIMEOUT"`         // Defaults to 15s
+	IdleTimeout      Duration       `json:"idleTimeout" env:"IDLE_TIMEOUT"`           // Defaults to 60s
+	MaxHeaderBytes   int            `json:"maxHeaderBytes" env:"MAX_HEADER_BYTES"`    // Defaults to 1 MB
+	TLSCertFile      string         `json:"tlsCertFile" env:"TLS_CERT_FILE"`          // Serve HTTPS when set together with TLSKeyFile
+	TLSKeyFile       string         `json:"tlsKeyFile" env:"TLS_KEY_FILE"`
+	ShutdownTimeout  Duration       `json:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`   // Time to drain requests and disconnect on shutdown, defaults to 30s
+	ReadinessTimeout Duration       `json:"readinessTimeout" env:"READINESS_TIMEOUT"` // Limit on each dependency check of /readyz, defaults to 2s
+	MetricsPath      string         `json:"metricsPath" env:"METRICS_PATH"`           // Where Prometheus metrics are served, defaults to /metrics; "off" disables them
+	TracingExporter  string         `json:"tracingExporter" env:"TRACING_EXPORTER"`   // none (default), stdout or otlp
+	TracingEndpoint  string         `json:"tracingEndpoint" env:"TRACING_ENDPOINT"`   // OTLP/HTTP collector such as collector:4318, over TLS unless it starts with http://; the OTEL_EXPORTER_OTLP_* variables apply when empty
+	LogLevel         string         `json:"logLevel" env:"LOG_LEVEL"`                 // debug, info (default), warn or error
+	LogFormat        string         `json:"logFormat" env:"LOG_FORMAT"`               // json (default) or text
+	RateLimits       RateLimits     `json:"rateLimits" env:"RATE_LIMITS"`             // By route group, auth and api; JSON object in the environment
+	Lockout          LockoutConfig  `json:"lockout" env:"LOCKOUT"`                    // JSON object in the environment
+	TrustedProxies   []string       `json:"trustedProxies" env:"TRUSTED_PROXIES"`     // Proxies whose X-Forwarded-For is believed, by address or CIDR; none when empty
+	Migrate          string         `json:"migrate" env:"MIGRATE"`                    // up (default) or off, see MigrateUp
+	TrashRetention   Duration       `json:"trashRetention" env:"TRASH_RETENTION"`     // How long deleted posts can be restored before they are purged, defaults to 720h
+	PurgeInterval    Duration       `json:"purgeInterval" env:"PURGE_INTERVAL"`       // Time between purges of the trash, defaults to 1h
 }
 
-func LoadConfig(configPath string) {
-	configFile, err := os.Open(configPath)
+// Duration is a time.Duration written like "15s" in config files and the environment
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
 	if err != nil {
-		log.Fatalf("Failed to open config file: %v", err)
+		return err
 	}
-	defer configFile.Close()
+	*d = Duration(parsed)
+	return nil
+}
+
+// RateLimitConfig lets Requests through per Per for each client address and
+// each account. Zero requests disable the limit.
+type RateLimitConfig struct {
+	Requests int      `json:"requests"`
+	Per      Duration `json:"per"`
+}
+
+// RateLimits are the limits of the route groups by name
+type RateLimits map[string]RateLimitConfig
+
+// DefaultRateLimits apply to the route groups missing from RateLimits
+var DefaultRateLimits = RateLimits{
+	"auth": {Requests: 10, Per: Duration(time.Minute)},
+	"api":  {Requests: 300, Per: Duration(time.Minute)},
+}
+
+// LockoutConfig locks an account out for Base after Threshold consecutive
+// failed logins, doubling with each further failure up to Max. Failures are
+// forgotten Window after the last one. A negative Threshold disables lockout.
+type LockoutConfig struct {
+	Threshold int      `json:"threshold"`
+	Base      Duration `json:"base"`
+	Max       Duration `json:"max"`
+	Window    Duration `json:"window"`
+}
+
+// JwtKeyConfig describes one asymmetric key. Keys that only verify tokens
+// issued before a rotation need just the public key.
+type JwtKeyConfig struct {
+	ID             string `json:"id"`
+	Algorithm      string `json:"algorithm"` // RS256 or EdDSA
+	PrivateKeyFile string `json:"privateKeyFile,omitempty"`
+	PublicKeyFile  string `json:"publicKeyFile,omitempty"`
+}
+
+// ResolveProfile picks the profile named by the flag, else by ProfileEnv, else ProfileLocal
+func ResolveProfile(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if profile := os.Getenv(ProfileEnv); profile != "" {
+		return profile
+	}
+	return ProfileLocal
+}
+
+// ProfilePath is the config file of profile inside dir
+func ProfilePath(dir string, profile string) string {
+	return filepath.Join(dir, "config_"+profile+".json")
+}
+
+// Load reads the config file of profile from dir, applies the environment
+// overrides, reads the secret files and validates the result into Config
+func Load(dir string, profile string) error {
+	if !slices.Contains(Profiles, profile) {
+		return fmt.Errorf("unknown profile %q, want one of %s", profile, strings.Join(Profiles, ", "))
+	}
+	if err := LoadConfig(ProfilePath(dir, profile)); err != nil {
+		return err
+	}
+	if err := applyEnv(&Config); err != nil {
+		return err
+	}
+	if err := Config.readSecretFiles(); err != nil {
+		return err
+	}
+	Config.setDefaults()
+	return Config.Validate()
+}
+
+// LoadConfig reads the JSON config file at configPath into Config
+func LoadConfig(configPath string) error {
+	configFile, err := os.Open(configPath)
 	if err != nil {
-		log.Fatalf("Failed to open config file: %v", err)
+		return fmt.Errorf("failed to open config file: %w", err)
 	}
 	defer configFile.Close()
 
-	decoder := json.NewDecoder(configFile)
-	if err := decoder.Decode(&Config); err != nil {
-		log.Fatalf("Failed to parse config file: %v", err)
+	var cfg Configuration
+	if err := json.NewDecoder(configFile).Decode(&cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	Config = cfg
+	return nil
+}
+
+// setDefaults fills in the server settings left empty and lower-cases the
+// log level and format, which are accepted in any case
+func (cfg *Configuration) setDefaults() {
+	if cfg.ServerAddr == "" {
+		cfg.ServerAddr = ":8080"
+	}
+	if cfg.ReadTimeout == 0 {
+		cfg.ReadTimeout = Duration(15 * time.Second)
+	}
+	if cfg.WriteTimeout == 0 {
+		cfg.WriteTimeout = Duration(15 * time.Second)
+	}
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = Duration(60 * time.Second)
+	}
+	if cfg.MaxHeaderBytes == 0 {
+		cfg.MaxHeaderBytes = 1 << 20
+	}
+	if cfg.ShutdownTimeout == 0 {
+		cfg.ShutdownTimeout = Duration(30 * time.Second)
+	}
+	if cfg.ReadinessTimeout == 0 {
+		cfg.ReadinessTimeout = Duration(2 * time.Second)
+	}
+	if cfg.MetricsPath == "" {
+		cfg.MetricsPath = DefaultMetricsPath
+	}
+	if cfg.TracingExporter == "" {
+		cfg.TracingExporter = "none"
+	}
+	cfg.LogLevel = strings.ToLower(cfg.LogLevel)
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = "info"
+	}
+	cfg.LogFormat = strings.ToLower(cfg.LogFormat)
+	if cfg.LogFormat == "" {
+		cfg.LogFormat = "json"
+	}
+	if cfg.Migrate == "" {
+		cfg.Migrate = MigrateUp
+	}
+	if cfg.TrashRetention == 0 {
+		cfg.TrashRetention = Duration(30 * 24 * time.Hour)
+	}
+	if cfg.PurgeInterval == 0 {
+		cfg.PurgeInterval = Duration(time.Hour)
+	}
+	for group, limit := range DefaultRateLimits {
+		if _, ok := cfg.RateLimits[group]; !ok {
+			if cfg.RateLimits == nil {
+				cfg.RateLimits = RateLimits{}
+			}
+			cfg.RateLimits[group] = limit
+		}
+	}
+	if cfg.Lockout.Threshold == 0 {
+		cfg.Lockout.Threshold = 5
+	}
+	if cfg.Lockout.Base == 0 {
+		cfg.Lockout.Base = Duration(time.Minute)
+	}
+	if cfg.Lockout.Max == 0 {
+		cfg.Lockout.Max = Duration(time.Hour)
+	}
+	if cfg.Lockout.Window == 0 {
+		cfg.Lockout.Window = Duration(15 * time.Minute)
+	}
+}
+
+// Validate reports every required field that is missing
+func (cfg Configuration) Validate() error {
+	var errs []error
+	if cfg.MongoURI == "" {
+		errs = append(errs, errors.New("mongoURI is required"))
+	}
+	if cfg.Database == "" {
+		errs = append(errs, errors.New("database is required"))
+	}
+	if len(cfg.JwtKeys) == 0 && cfg.JwtKey == "" {
+		errs = append(errs, errors.New("jwtKey is required when no jwtKeys are configured"))
+	}
+	if len(cfg.JwtKeys) > 0 && cfg.JwtSigningKeyID == "" {
+		errs = append(errs, errors.New("jwtSigningKeyId is required with jwtKeys"))
+	}
+	for i, key := range cfg.JwtKeys {
+		if key.ID == "" || key.Algorithm == "" {
+			errs = append(errs, fmt.Errorf("jwtKeys[%d] needs an id and an algorithm", i))
+		}
+		if key.PrivateKeyFile == "" && key.PublicKeyFile == "" {
+			errs = append(errs, fmt.Errorf("jwtKeys[%d] needs a privateKeyFile or publicKeyFile", i))
+		}
+	}
+	if cfg.MetricsPath != "" && cfg.MetricsPath != MetricsOff && !strings.HasPrefix(cfg.MetricsPath, "/") {
+		errs = append(errs, errors.New(`metricsPath must start with "/" or be "off"`))
+	}
+	if !slices.Contains(tracingExporters, cfg.TracingExporter) {
+		errs = append(errs, fmt.Errorf("tracingExporter must be one of %s", strings.Join(tracingExporters, ", ")))
+	}
+	if !slices.Contains(logLevels, strings.ToLower(cfg.LogLevel)) {
+		errs = append(errs, fmt.Errorf("logLevel must be one of %s", strings.Join(logLevels, ", ")))
+	}
+	if !slices.Contains(logFormats, strings.ToLower(cfg.LogFormat)) {
+		errs = append(errs, fmt.Errorf("logFormat must be one of %s", strings.Join(logFormats, ", ")))
+	}
+	if !slices.Contains(migrateModes, cfg.Migrate) {
+		errs = append(errs, fmt.Errorf("migrate must be one of %s", strings.Join(migrateModes, ", ")))
+	}
+	if cfg.TrashRetention < 0 {
+		errs = append(errs, errors.New("trashRetention must not be negative"))
+	}
+	if cfg.PurgeInterval < 0 {
+		errs = append(errs, errors.New("purgeInterval must not be negative"))
+	}
+	for group, limit := range cfg.RateLimits {
+		if limit.Requests > 0 && limit.Per <= 0 {
+			errs = append(errs, fmt.Errorf("rateLimits.%s needs a positive per", group))
+		}
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		errs = append(errs, errors.New("tlsCertFile and tlsKeyFile must be set together"))
+	}
+	return errors.Join(errs...)
+}
+
+// readSecretFiles replaces the secrets with the contents of their files, when set
+func (cfg *Configuration) readSecretFiles() error {
+	secrets := []struct {
+		path  string
+		value *string
+	}{
+		{cfg.JwtKeyFile, &cfg.JwtKey},
+		{cfg.MongoURIFile, &cfg.MongoURI},
+	}
+	for _, secret := range secrets {
+		if secret.path == "" {
+			continue
+		}
+		b, err := os.ReadFile(secret.path)
+		if err != nil {
+			return fmt.Errorf("failed to read secret file: %w", err)
+		}
+		*secret.value = strings.TrimSpace(string(b))
+	}
+	return nil
+}
+
+// applyEnv overrides every field of cfg that has an env tag from its variable, when set
+func applyEnv(cfg *Configuration) error {
+	v := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name, ok := v.Type().Field(i).Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		raw, ok := os.LookupEnv(EnvPrefix + name)
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(i), raw); err != nil {
+			return fmt.Errorf("invalid %s%s: %w", EnvPrefix, name, err)
+		}
+	}
+	return nil
+}
+
+// setField parses raw into field according to its type. Lists of strings are
+// comma separated and anything without a plain text form is JSON.
+func setField(field reflect.Value, raw string) error {
+	switch {
+	case field.Type() == reflect.TypeOf(Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+	case field.Kind() == reflect.Int || field.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case field.Type() == reflect.TypeOf([]string(nil)):
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return json.Unmarshal([]byte(raw), field.Addr().Interface())
 	}
+	return nil
 }
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeProfile writes contents as the config file of profile in a new directory
+func writeProfile(t *testing.T, profile string, contents string) string {
+	dir := t.TempDir()
+	err := os.WriteFile(ProfilePath(dir, profile), []byte(contents), 0o600)
+	assert.NoError(t, err)
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeProfile(t, ProfileLocal, `{
+		"jwtKey": "your_secret_key",
+		"mongoURI": "mongodb://localhost:27017",
+		"database": "social_media",
+		"reactionTypes": ["like"]
+	}`)
+	t.Setenv("APP_DATABASE", "social_media_test")
+	t.Setenv("APP_REACTION_TYPES", "like, love ,")
+	t.Setenv("APP_JWT_KEYS", `[{"id":"2024-01","algorithm":"EdDSA","publicKeyFile":"keys/2024-01.pub"}]`)
+	t.Setenv("APP_JWT_SIGNING_KEY_ID", "2024-01")
+	t.Setenv("APP_READ_TIMEOUT", "5s")
+	t.Setenv("APP_MAX_HEADER_BYTES", "4096")
+	t.Setenv("APP_RATE_LIMITS", `{"auth": {"requests": 5, "per": "1m"}}`)
+	t.Setenv("APP_LOG_LEVEL", "DEBUG")
+	t.Setenv("APP_LOG_FORMAT", "Text")
+
+	err := Load(dir, ProfileLocal)
+	assert.NoError(t, err)
+	assert.Equal(t, "mongodb://localhost:27017", Config.MongoURI)
+	assert.Equal(t, "social_media_test", Config.Database)
+	assert.Equal(t, []string{"like", "love"}, Config.ReactionTypes)
+	assert.Equal(t, []JwtKeyConfig{{ID: "2024-01", Algorithm: "EdDSA", PublicKeyFile: "keys/2024-01.pub"}}, Config.JwtKeys)
+	assert.Equal(t, Duration(5*time.Second), Config.ReadTimeout)
+	assert.Equal(t, 4096, Config.MaxHeaderBytes)
+	// The log settings are accepted in any case
+	assert.Equal(t, "debug", Config.LogLevel)
+	assert.Equal(t, "text", Config.LogFormat)
+	// Server settings left out fall back to their defaults
+	assert.Equal(t, ":8080", Config.ServerAddr)
+	assert.Equal(t, Duration(15*time.Second), Config.WriteTimeout)
+	assert.Equal(t, Duration(30*time.Second), Config.ShutdownTimeout)
+	assert.Equal(t, DefaultMetricsPath, Config.MetricsPath)
+	assert.Equal(t, RateLimitConfig{Requests: 5, Per: Duration(time.Minute)}, Config.RateLimits["auth"])
+	assert.Equal(t, DefaultRateLimits["api"], Config.RateLimits["api"])
+	assert.Equal(t, 5, Config.Lockout.Threshold)
+	assert.Equal(t, MigrateUp, Config.Migrate)
+	assert.Equal(t, Duration(30*24*time.Hour), Config.TrashRetention)
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	dir := writeProfile(t, ProfileProduction, `{"database": "social_media"}`)
+	secrets := t.TempDir()
+	jwtKeyFile := filepath.Join(secrets, "jwt_key")
+	mongoURIFile := filepath.Join(secrets, "mongo_uri")
+	os.WriteFile(jwtKeyFile, []byte("file_secret\n"), 0o600)
+	os.WriteFile(mongoURIFile, []byte("mongodb://db:27017\n"), 0o600)
+	t.Setenv("APP_JWT_KEY_FILE", jwtKeyFile)
+	t.Setenv("APP_MONGO_URI_FILE", mongoURIFile)
+
+	err := Load(dir, ProfileProduction)
+	assert.NoError(t, err)
+	assert.Equal(t, "file_secret", Config.JwtKey)
+	assert.Equal(t, "mongodb://db:27017", Config.MongoURI)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := writeProfile(t, ProfileProduction, `{"jwtKeys": [{"id": "2024-01"}], "tlsCertFile": "server.crt", "idleTimeout": "2m", "metricsPath": "metrics", "tracingExporter": "jaeger", "logLevel": "verbose", "migrate": "down", "trashRetention": "-1h"}`)
+
+	err := Load(dir, ProfileProduction)
+	assert.ErrorContains(t, err, "mongoURI is required")
+	assert.ErrorContains(t, err, "database is required")
+	assert.ErrorContains(t, err, "jwtSigningKeyId is required")
+	assert.ErrorContains(t, err, "jwtKeys[0] needs an id and an algorithm")
+	assert.ErrorContains(t, err, "tlsCertFile and tlsKeyFile must be set together")
+	assert.ErrorContains(t, err, "metricsPath must start with")
+	assert.ErrorContains(t, err, "tracingExporter must be one of none, stdout, otlp")
+	assert.ErrorContains(t, err, "logLevel must be one of debug, info, warn, error")
+	assert.ErrorContains(t, err, "migrate must be one of up, off")
+	assert.ErrorContains(t, err, "trashRetention must not be negative")
+
+	err = Load(dir, "staging")
+	assert.ErrorContains(t, err, "unknown profile")
+
+	t.Setenv("APP_JWT_KEYS", "not json")
+	err = Load(dir, ProfileProduction)
+	assert.ErrorContains(t, err, "invalid APP_JWT_KEYS")
+}
+
+func TestResolveProfile(t *testing.T) {
+	t.Setenv(ProfileEnv, "")
+	assert.Equal(t, ProfileLocal, ResolveProfile(""))
+
+	t.Setenv(ProfileEnv, ProfileProduction)
+	assert.Equal(t, ProfileProduction, ResolveProfile(""))
+	assert.Equal(t, ProfileUnittest, ResolveProfile(ProfileUnittest))
+}
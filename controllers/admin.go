@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdminController serves the moderation endpoints. Routes restrict them to
+// moderators and admins with middlewares.RequireRole.
+type AdminController struct {
+	Users repositories.UserRepository
+	Posts *PostController
+}
+
+func NewAdminController(users repositories.UserRepository, posts *PostController) *AdminController {
+	return &AdminController{Users: users, Posts: posts}
+}
+
+func userSummary(user models.User) models.UserSummary {
+	return models.UserSummary{
+		ID:        user.ID,
+		Username:  user.Username,
+		Role:      user.EffectiveRole(),
+		Suspended: user.Suspended,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// pathTarget loads the user named by the :username path parameter that the
+// caller acts on. Nobody acts on themselves, and moderators only act on users.
+func (ac *AdminController) pathTarget(c *gin.Context) (models.User, error) {
+	caller, err := currentUser(c, ac.Users)
+	if err != nil {
+		return caller, err
+	}
+	target, err := ac.Users.FindByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		return target, lookupError(err, "User not found")
+	}
+	if target.ID == caller.ID {
+		return target, apperrors.Forbidden("You cannot do this to yourself")
+	}
+	if caller.Role != models.RoleAdmin && target.EffectiveRole() != models.RoleUser {
+		return target, apperrors.Forbidden("Only admins can do this to moderators and admins")
+	}
+	return target, nil
+}
+
+// ListUsers godoc
+//
+//	@Summary		List Users
+//	@Description	List every user, newest first. Admins only.
+//	@ID				ListUsers
+//	@Tags			admin
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			limit	query		int				false	"page size, at most 100"	default(20)
+//	@Param			cursor	query		string			false	"next_cursor or prev_cursor from a previous page"
+//	@Success		200		{object}	models.UserPage	"OK"
+//	@Failure		400		{object}	models.Response	"Bad Request"
+//	@Failure		401		{object}	models.Response	"Unauthorized"
+//	@Failure		403		{object}	models.Response	"Forbidden"
+//	@Failure		500		{object}	models.Response	"Internal Server Error"
+//	@Router			/admin/users [get]
+func (ac *AdminController) ListUsers(c *gin.Context) {
+	page, err := parsePage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	users, err := ac.Users.List(c.Request.Context(), fetchPage(page))
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	users, hasMore := trimPage(page, users)
+	summaries := make([]models.UserSummary, len(users))
+	for i, user := range users {
+		summaries[i] = userSummary(user)
+	}
+	var first, last primitive.ObjectID
+	if len(users) > 0 {
+		first, last = users[0].ID, users[len(users)-1].ID
+	}
+	c.JSON(http.StatusOK, models.UserPage{
+		Data:       summaries,
+		Pagination: newPagination(page, hasMore, first, last),
+	})
+}
+
+// SuspendUser godoc
+//
+//	@Summary		Suspend User
+//	@Description	Suspend a user, who can then neither log in nor use their tokens. Moderators may only suspend users.
+//	@ID				SuspendUser
+//	@Tags			admin
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			username	path		string			true	"username to suspend"
+//	@Success		200			{object}	models.Response	"OK"
+//	@Failure		401			{object}	models.Response	"Unauthorized"
+//	@Failure		403			{object}	models.Response	"Forbidden"
+//	@Failure		404			{object}	models.Response	"Not Found"
+//	@Failure		500			{object}	models.Response	"Internal Server Error"
+//	@Router			/admin/users/{username}/suspension [post]
+func (ac *AdminController) SuspendUser(c *gin.Context) {
+	ac.setSuspended(c, true, "Suspended ")
+}
+
+// UnsuspendUser godoc
+//
+//	@Summary		Unsuspend User
+//	@Description	Lift the suspension of a user. Moderators may only unsuspend users.
+//	@ID				UnsuspendUser
+//	@Tags			admin
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			username	path		string			true	"username to unsuspend"
+//	@Success		200			{object}	models.Response	"OK"
+//	@Failure		401			{object}	models.Response	"Unauthorized"
+//	@Failure		403			{object}	models.Response	"Forbidden"
+//	@Failure		404			{object}	models.Response	"Not Found"
+//	@Failure		500			{object}	models.Response	"Internal Server Error"
+//	@Router			/admin/users/{username}/suspension [delete]
+func (ac *AdminController) UnsuspendUser(c *gin.Context) {
+	ac.setSuspended(c, false, "Unsuspended ")
+}
+
+func (ac *AdminController) setSuspended(c *gin.Context, suspended bool, message string) {
+	target, err := ac.pathTarget(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := ac.Users.SetSuspended(c.Request.Context(), target.Username, suspended); err != nil {
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Message: message + target.Username,
+	})
+}
+
+// SetRole godoc
+//
+//	@Summary		Set Role
+//	@Description	Give a user the role user, moderator or admin. Admins only.
+//	@ID				SetRole
+//	@Tags			admin
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			username	path		string				true	"username"
+//	@Param			role		body		models.RoleInput	true	"new role"
+//	@Success		200			{object}	models.UserSummary	"OK"
+//	@Failure		400			{object}	models.Response		"Bad Request"
+//	@Failure		401			{object}	models.Response		"Unauthorized"
+//	@Failure		403			{object}	models.Response		"Forbidden"
+//	@Failure		404			{object}	models.Response		"Not Found"
+//	@Failure		422			{object}	models.Response		"Unprocessable Entity"
+//	@Failure		500			{object}	models.Response		"Internal Server Error"
+//	@Router			/admin/users/{username}/role [put]
+func (ac *AdminController) SetRole(c *gin.Context) {
+	var input models.RoleInput
+	if err := bindJSON(c, &input); err != nil {
+		c.Error(err)
+		return
+	}
+	target, err := ac.pathTarget(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := ac.Users.SetRole(c.Request.Context(), target.Username, input.Role); err != nil {
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	updated, err := ac.Users.FindByUsername(c.Request.Context(), target.Username)
+	if err != nil {
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	c.JSON(http.StatusOK, userSummary(updated))
+}
+
+// DeletePost godoc
+//
+//	@Summary		Delete Any Post
+//	@Description	Move any user's post to the trash; only an admin can restore it. The post must still be at the version given in If-Match or in the body, so a moderator never removes an edit they have not seen.
+//	@ID				AdminDeletePost
+//	@Tags			admin
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string				true	"id of post to be deleted"
+//	@Param			If-Match	header		string				false	"ETag of the post being deleted; required unless the body has its version"
+//	@Param			version		body		models.PostVersion	false	"version of the post being deleted"
+//	@Success		200			{object}	models.Response		"OK"
+//	@Failure		400			{object}	models.Response		"Bad Request"
+//	@Failure		401			{object}	models.Response		"Unauthorized"
+//	@Failure		403			{object}	models.Response		"Forbidden"
+//	@Failure		404			{object}	models.Response		"Not Found"
+//	@Failure		412			{object}	models.Response		"Precondition Failed"
+//	@Failure		428			{object}	models.Response		"Precondition Required"
+//	@Failure		500			{object}	models.Response		"Internal Server Error"
+//	@Router			/admin/posts/{id} [delete]
+func (ac *AdminController) DeletePost(c *gin.Context) {
+	user, err := currentUser(c, ac.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	post, err := pathPost(c, ac.Posts.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	version, err := bodyVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := checkVersion(c, post, version); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := ac.Posts.moveToTrash(c, post, user); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Post moved to trash",
+	})
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAdminRouter serves the admin routes acting as username. Routes restrict
+// them by role; these tests exercise the checks the handlers make themselves.
+func newAdminRouter(repos *repositories.Repositories, username string) *gin.Engine {
+	adminController := NewAdminController(repos.Users, newTestPostController(repos))
+
+	router := newTestRouter()
+	router.Use(withUser(username))
+	router.GET("/admin/users", adminController.ListUsers)
+	router.POST("/admin/users/:username/suspension", adminController.SuspendUser)
+	router.DELETE("/admin/users/:username/suspension", adminController.UnsuspendUser)
+	router.PUT("/admin/users/:username/role", adminController.SetRole)
+	router.DELETE("/admin/posts/:id", adminController.DeletePost)
+	return router
+}
+
+func putRole(router *gin.Engine, username string, role string) *httptest.ResponseRecorder {
+	jsonValue, _ := json.Marshal(models.RoleInput{Role: role})
+	req, _ := http.NewRequest("PUT", "/admin/users/"+username+"/role", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestListUsers(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "alice", models.RoleAdmin)
+	insertTestUser(repos, "bob", models.RoleUser)
+	router := newAdminRouter(repos, "alice")
+
+	recorder := serve(router, "GET", "/admin/users?limit=1")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "password")
+	var page models.UserPage
+	err := json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.NoError(t, err)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "bob", page.Data[0].Username)
+	assert.True(t, page.Pagination.HasMore)
+}
+
+func TestSuspendUser(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "mod", models.RoleModerator)
+	insertTestUser(repos, "bob", models.RoleUser)
+	router := newAdminRouter(repos, "mod")
+
+	recorder := serve(router, "POST", "/admin/users/bob/suspension")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	bob, _ := repos.Users.FindByUsername(context.TODO(), "bob")
+	assert.True(t, bob.Suspended)
+
+	recorder = serve(router, "DELETE", "/admin/users/bob/suspension")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	bob, _ = repos.Users.FindByUsername(context.TODO(), "bob")
+	assert.False(t, bob.Suspended)
+
+	recorder = serve(router, "POST", "/admin/users/nobody/suspension")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// Accounts created before roles were stored are users
+	insertTestUser(repos, "legacy", "")
+	recorder = serve(router, "POST", "/admin/users/legacy/suspension")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestSuspendUserForbidden(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "mod", models.RoleModerator)
+	insertTestUser(repos, "alice", models.RoleAdmin)
+	router := newAdminRouter(repos, "mod")
+
+	// Moderators cannot suspend admins...
+	recorder := serve(router, "POST", "/admin/users/alice/suspension")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// ...nor themselves
+	recorder = serve(router, "POST", "/admin/users/mod/suspension")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Admins can suspend moderators
+	recorder = serve(newAdminRouter(repos, "alice"), "POST", "/admin/users/mod/suspension")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestSetRole(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "alice", models.RoleAdmin)
+	insertTestUser(repos, "bob", models.RoleUser)
+	router := newAdminRouter(repos, "alice")
+
+	recorder := putRole(router, "bob", models.RoleModerator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	bob, _ := repos.Users.FindByUsername(context.TODO(), "bob")
+	assert.Equal(t, models.RoleModerator, bob.Role)
+
+	recorder = putRole(router, "bob", "superuser")
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder = putRole(router, "alice", models.RoleUser)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestAdminDeletePost(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "mod", models.RoleModerator)
+	post := insertTestPost(repos, insertTestUser(repos, "bob", models.RoleUser))
+	router := newAdminRouter(repos, "mod")
+
+	// A moderator must have seen the version they remove
+	recorder := serve(router, "DELETE", "/admin/posts/"+post.ID.Hex())
+	assert.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+
+	recorder = serveIfMatch(router, "DELETE", "/admin/posts/"+post.ID.Hex(), `"1"`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	_, err := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	recorder = serveIfMatch(router, "DELETE", "/admin/posts/"+post.ID.Hex(), `"1"`)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
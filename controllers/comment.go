@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommentController serves the comment threads of posts
+type CommentController struct {
+	Comments repositories.CommentRepository
+	Posts    repositories.PostRepository
+	Users    repositories.UserRepository
+}
+
+func NewCommentController(comments repositories.CommentRepository, posts repositories.PostRepository, users repositories.UserRepository) *CommentController {
+	return &CommentController{Comments: comments, Posts: posts, Users: users}
+}
+
+// pathComment loads the live comment named by the :commentId path parameter on post
+func (cc *CommentController) pathComment(c *gin.Context, post models.Post) (models.Comment, error) {
+	objID, err := pathID(c, "commentId", "comment")
+	if err != nil {
+		return models.Comment{}, err
+	}
+	comment, err := cc.Comments.FindByID(c.Request.Context(), objID)
+	if err != nil {
+		return comment, lookupError(err, "Comment not found")
+	}
+	if comment.PostID != post.ID || comment.Deleted {
+		return comment, apperrors.NotFound("Comment not found")
+	}
+	return comment, nil
+}
+
+// CreateComment godoc
+//
+//	@Summary		Create Comment
+//	@Description	Comment on a post, or reply to one of its comments with parent_id
+//	@ID				CreateComment
+//	@Tags			comment
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"id of the post"
+//	@Param			comment	body		models.CommentInput	true	"Comment to create"
+//	@Success		201		{object}	models.Comment		"Created"
+//	@Failure		400		{object}	models.Response		"Bad Request"
+//	@Failure		401		{object}	models.Response		"Unauthorized"
+//	@Failure		404		{object}	models.Response		"Not Found"
+//	@Failure		409		{object}	models.Response		"Conflict"
+//	@Failure		422		{object}	models.Response		"Unprocessable Entity"
+//	@Failure		500		{object}	models.Response		"Internal Server Error"
+//	@Router			/posts/{id}/comments [post]
+func (cc *CommentController) CreateComment(c *gin.Context) {
+	user, err := currentUser(c, cc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	post, err := pathPost(c, cc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var input models.CommentInput
+	if err := bindJSON(c, &input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if input.ParentID != nil {
+		parent, err := cc.Comments.FindByID(ctx, *input.ParentID)
+		if errors.Is(err, repositories.ErrNotFound) || (err == nil && parent.PostID != post.ID) {
+			c.Error(apperrors.NotFound("Parent comment not found"))
+			return
+		}
+		if err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+		if parent.Deleted {
+			c.Error(apperrors.Conflict("Cannot reply to a deleted comment"))
+			return
+		}
+	}
+
+	comment := models.Comment{
+		ID:        primitive.NewObjectID(),
+		PostID:    post.ID,
+		ParentID:  input.ParentID,
+		AuthorID:  user.ID,
+		Author:    user.Username,
+		Content:   input.Content,
+		CreatedAt: time.Now(),
+	}
+	if err := cc.Comments.Create(ctx, comment); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	if err := cc.Posts.IncrementCommentCount(ctx, post.ID, 1); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	if comment.ParentID != nil {
+		if err := cc.Comments.IncrementReplyCount(ctx, *comment.ParentID, 1); err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// GetComments godoc
+//
+//	@Summary		Get Comments
+//	@Description	Get one thread level of a post's comments, newest first: the top level comments, or the replies to parent_id
+//	@ID				GetComments
+//	@Tags			comment
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id			path		string				true	"id of the post"
+//	@Param			parent_id	query		string				false	"id of the comment whose replies to list"
+//	@Param			limit		query		int					false	"page size, at most 100"	default(20)
+//	@Param			cursor		query		string				false	"next_cursor or prev_cursor from a previous page"
+//	@Success		200			{object}	models.CommentPage	"OK"
+//	@Failure		400			{object}	models.Response		"Bad Request"
+//	@Failure		401			{object}	models.Response		"Unauthorized"
+//	@Failure		404			{object}	models.Response		"Not Found"
+//	@Failure		500			{object}	models.Response		"Internal Server Error"
+//	@Router			/posts/{id}/comments [get]
+func (cc *CommentController) GetComments(c *gin.Context) {
+	page, err := parsePage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var parentID *primitive.ObjectID
+	if raw := c.Query("parent_id"); raw != "" {
+		objID, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			c.Error(apperrors.InvalidID("parent comment"))
+			return
+		}
+		parentID = &objID
+	}
+	post, err := pathPost(c, cc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	comments, err := cc.Comments.List(c.Request.Context(), post.ID, parentID, fetchPage(page))
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	comments, hasMore := trimPage(page, comments)
+	var first, last primitive.ObjectID
+	if len(comments) > 0 {
+		first, last = comments[0].ID, comments[len(comments)-1].ID
+	}
+	c.JSON(http.StatusOK, models.CommentPage{
+		Data:       comments,
+		Pagination: newPagination(page, hasMore, first, last),
+	})
+}
+
+// UpdateComment godoc
+//
+//	@Summary		Update Comment
+//	@Description	Edit the content of a comment. Only its author may edit it.
+//	@ID				UpdateComment
+//	@Tags			comment
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string				true	"id of the post"
+//	@Param			commentId	path		string				true	"id of the comment"
+//	@Param			comment		body		models.CommentInput	true	"New content; parent_id is ignored"
+//	@Success		200			{object}	models.Comment		"OK"
+//	@Failure		400			{object}	models.Response		"Bad Request"
+//	@Failure		401			{object}	models.Response		"Unauthorized"
+//	@Failure		403			{object}	models.Response		"Forbidden"
+//	@Failure		404			{object}	models.Response		"Not Found"
+//	@Failure		422			{object}	models.Response		"Unprocessable Entity"
+//	@Failure		500			{object}	models.Response		"Internal Server Error"
+//	@Router			/posts/{id}/comments/{commentId} [put]
+func (cc *CommentController) UpdateComment(c *gin.Context) {
+	user, err := currentUser(c, cc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	post, err := pathPost(c, cc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	comment, err := cc.pathComment(c, post)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if comment.AuthorID != user.ID {
+		c.Error(apperrors.Forbidden("You are not allowed to update this comment"))
+		return
+	}
+	var input models.CommentInput
+	if err := bindJSON(c, &input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := cc.Comments.UpdateContent(ctx, comment.ID, input.Content); err != nil {
+		c.Error(lookupError(err, "Comment not found"))
+		return
+	}
+	comment, err = cc.Comments.FindByID(ctx, comment.ID)
+	if err != nil {
+		c.Error(lookupError(err, "Comment not found"))
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment godoc
+//
+//	@Summary		Delete Comment
+//	@Description	Delete a comment, leaving a tombstone so its replies stay in the thread. Only its author or an admin may delete it.
+//	@ID				DeleteComment
+//	@Tags			comment
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id			path		string			true	"id of the post"
+//	@Param			commentId	path		string			true	"id of the comment"
+//	@Success		200			{object}	models.Response	"OK"
+//	@Failure		400			{object}	models.Response	"Bad Request"
+//	@Failure		401			{object}	models.Response	"Unauthorized"
+//	@Failure		403			{object}	models.Response	"Forbidden"
+//	@Failure		404			{object}	models.Response	"Not Found"
+//	@Failure		500			{object}	models.Response	"Internal Server Error"
+//	@Router			/posts/{id}/comments/{commentId} [delete]
+func (cc *CommentController) DeleteComment(c *gin.Context) {
+	user, err := currentUser(c, cc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	post, err := pathPost(c, cc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	comment, err := cc.pathComment(c, post)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if comment.AuthorID != user.ID && user.Role != models.RoleAdmin {
+		c.Error(apperrors.Forbidden("You are not allowed to delete this comment"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := cc.Comments.Tombstone(ctx, comment.ID); err != nil {
+		// A concurrent delete may have tombstoned it first
+		c.Error(lookupError(err, "Comment not found"))
+		return
+	}
+	if err := cc.Posts.IncrementCommentCount(ctx, post.ID, -1); err != nil {
+		c.Error(lookupError(err, "Post not found"))
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Comment deleted successfully",
+	})
+}
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newCommentRouter serves the comment routes and post deletion acting as username
+func newCommentRouter(repos *repositories.Repositories, username string) *gin.Engine {
+	commentController := NewCommentController(repos.Comments, repos.Posts, repos.Users)
+	postController := newTestPostController(repos)
+
+	router := newTestRouter()
+	router.Use(withUser(username))
+	router.GET("/posts/:id", postController.GetPost)
+	router.DELETE("/posts/:id", postController.DeletePost)
+	router.POST("/posts/:id/comments", commentController.CreateComment)
+	router.GET("/posts/:id/comments", commentController.GetComments)
+	router.PUT("/posts/:id/comments/:commentId", commentController.UpdateComment)
+	router.DELETE("/posts/:id/comments/:commentId", commentController.DeleteComment)
+	return router
+}
+
+func insertTestPost(repos *repositories.Repositories, author models.User) models.Post {
+	post := models.Post{
+		ID:       primitive.NewObjectID(),
+		Title:    "Test Post",
+		Content:  "This is a test post",
+		AuthorID: author.ID,
+		Author:   author.Username,
+		Version:  1,
+	}
+	repos.Posts.Create(context.Background(), post)
+	return post
+}
+
+func postComment(router *gin.Engine, postID primitive.ObjectID, input models.CommentInput) (*httptest.ResponseRecorder, models.Comment) {
+	body, _ := json.Marshal(input)
+	req, _ := http.NewRequest("POST", "/posts/"+postID.Hex()+"/comments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	var comment models.Comment
+	json.Unmarshal(recorder.Body.Bytes(), &comment)
+	return recorder, comment
+}
+
+func TestCreateComment(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	insertTestUser(repos, "bob", models.RoleUser)
+	post := insertTestPost(repos, alice)
+	router := newCommentRouter(repos, "bob")
+
+	recorder, comment := postComment(router, post.ID, models.CommentInput{Content: "Nice post"})
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "bob", comment.Author)
+	assert.Equal(t, post.ID, comment.PostID)
+	assert.Nil(t, comment.ParentID)
+
+	recorder, reply := postComment(router, post.ID, models.CommentInput{Content: "Thanks", ParentID: &comment.ID})
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, comment.ID, *reply.ParentID)
+
+	parent, _ := repos.Comments.FindByID(context.Background(), comment.ID)
+	assert.Equal(t, int64(1), parent.ReplyCount)
+	stored, _ := repos.Posts.FindByID(context.Background(), post.ID)
+	assert.Equal(t, int64(2), stored.CommentCount)
+}
+
+func TestCreateCommentInvalid(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	post := insertTestPost(repos, alice)
+	other := insertTestPost(repos, alice)
+	router := newCommentRouter(repos, "alice")
+
+	recorder, _ := postComment(router, post.ID, models.CommentInput{Content: "  "})
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder, _ = postComment(router, primitive.NewObjectID(), models.CommentInput{Content: "Hello"})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// A parent on another post is not part of this thread
+	_, comment := postComment(router, other.ID, models.CommentInput{Content: "Hello"})
+	recorder, _ = postComment(router, post.ID, models.CommentInput{Content: "Reply", ParentID: &comment.ID})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestGetCommentsByLevel(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	post := insertTestPost(repos, alice)
+	router := newCommentRouter(repos, "alice")
+
+	_, first := postComment(router, post.ID, models.CommentInput{Content: "First"})
+	postComment(router, post.ID, models.CommentInput{Content: "Second"})
+	for i := 0; i < 3; i++ {
+		postComment(router, post.ID, models.CommentInput{Content: "Reply", ParentID: &first.ID})
+	}
+
+	recorder := serve(router, "GET", "/posts/"+post.ID.Hex()+"/comments")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var topLevel models.CommentPage
+	json.Unmarshal(recorder.Body.Bytes(), &topLevel)
+	assert.Len(t, topLevel.Data, 2)
+	assert.Equal(t, "Second", topLevel.Data[0].Content)
+
+	recorder = serve(router, "GET", "/posts/"+post.ID.Hex()+"/comments?limit=2&parent_id="+first.ID.Hex())
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var replies models.CommentPage
+	json.Unmarshal(recorder.Body.Bytes(), &replies)
+	assert.Len(t, replies.Data, 2)
+	assert.True(t, replies.Pagination.HasMore)
+
+	recorder = serve(router, "GET", "/posts/"+post.ID.Hex()+"/comments?limit=2&parent_id="+first.ID.Hex()+"&cursor="+replies.Pagination.NextCursor)
+	json.Unmarshal(recorder.Body.Bytes(), &replies)
+	assert.Len(t, replies.Data, 1)
+	assert.False(t, replies.Pagination.HasMore)
+}
+
+func TestUpdateComment(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	insertTestUser(repos, "bob", models.RoleUser)
+	post := insertTestPost(repos, alice)
+	_, comment := postComment(newCommentRouter(repos, "alice"), post.ID, models.CommentInput{Content: "Original"})
+	path := "/posts/" + post.ID.Hex() + "/comments/" + comment.ID.Hex()
+	body, _ := json.Marshal(models.CommentInput{Content: "Edited"})
+
+	req, _ := http.NewRequest("PUT", path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	newCommentRouter(repos, "bob").ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	req, _ = http.NewRequest("PUT", path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder = httptest.NewRecorder()
+	newCommentRouter(repos, "alice").ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var updated models.Comment
+	json.Unmarshal(recorder.Body.Bytes(), &updated)
+	assert.Equal(t, "Edited", updated.Content)
+	assert.NotNil(t, updated.EditedAt)
+}
+
+func TestDeleteComment(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	insertTestUser(repos, "bob", models.RoleUser)
+	insertTestUser(repos, "admin", models.RoleAdmin)
+	post := insertTestPost(repos, alice)
+	router := newCommentRouter(repos, "alice")
+	_, comment := postComment(router, post.ID, models.CommentInput{Content: "Hello"})
+	postComment(router, post.ID, models.CommentInput{Content: "Reply", ParentID: &comment.ID})
+	path := "/posts/" + post.ID.Hex() + "/comments/" + comment.ID.Hex()
+
+	recorder := serve(newCommentRouter(repos, "bob"), "DELETE", path)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = serve(newCommentRouter(repos, "admin"), "DELETE", path)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The tombstone keeps its place in the thread so replies stay reachable
+	recorder = serve(router, "GET", "/posts/"+post.ID.Hex()+"/comments")
+	var page models.CommentPage
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.Len(t, page.Data, 1)
+	assert.True(t, page.Data[0].Deleted)
+	assert.Empty(t, page.Data[0].Content)
+	assert.Equal(t, int64(1), page.Data[0].ReplyCount)
+
+	stored, _ := repos.Posts.FindByID(context.Background(), post.ID)
+	assert.Equal(t, int64(1), stored.CommentCount)
+
+	recorder = serve(router, "DELETE", path)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+// liveComments reads tombstoned comments as if they were not, like a delete
+// that read the comment just before a concurrent one tombstoned it
+type liveComments struct {
+	repositories.CommentRepository
+}
+
+func (r liveComments) FindByID(ctx context.Context, id primitive.ObjectID) (models.Comment, error) {
+	comment, err := r.CommentRepository.FindByID(ctx, id)
+	comment.Deleted = false
+	return comment, err
+}
+
+func TestDeleteCommentTwiceConcurrently(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	_, comment := postComment(newCommentRouter(repos, "alice"), post.ID, models.CommentInput{Content: "Hello"})
+	postComment(newCommentRouter(repos, "alice"), post.ID, models.CommentInput{Content: "Kept"})
+	repos.Comments = liveComments{repos.Comments}
+	router := newCommentRouter(repos, "alice")
+	path := "/posts/" + post.ID.Hex() + "/comments/" + comment.ID.Hex()
+
+	recorder := serve(router, "DELETE", path)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	recorder = serve(router, "DELETE", path)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// Only the delete that tombstoned the comment counted it
+	stored, _ := repos.Posts.FindByID(context.Background(), post.ID)
+	assert.Equal(t, int64(1), stored.CommentCount)
+}
+
+func TestDeletePostHidesComments(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	post := insertTestPost(repos, alice)
+	router := newCommentRouter(repos, "alice")
+	_, comment := postComment(router, post.ID, models.CommentInput{Content: "Hello"})
+
+	recorder := serveIfMatch(router, "DELETE", "/posts/"+post.ID.Hex(), `"1"`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The thread is out of reach while the post is in the trash, but kept for a restore
+	recorder = serve(router, "GET", "/posts/"+post.ID.Hex()+"/comments")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	_, err := repos.Comments.FindByID(context.Background(), comment.ID)
+	assert.NoError(t, err)
+}
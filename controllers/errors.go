@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// errSuspended rejects the logins and tokens of suspended users
+var errSuspended = apperrors.Forbidden("Account is suspended")
+
+// pathID reads the ObjectID in the path parameter param; name says what it identifies
+func pathID(c *gin.Context, param string, name string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(c.Param(param))
+	if err != nil {
+		return id, apperrors.InvalidID(name)
+	}
+	return id, nil
+}
+
+// lookupError reports repositories.ErrNotFound as a 404 with message and any other
+// repository error as an internal error
+func lookupError(err error, message string) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return apperrors.NotFound(message)
+	}
+	return apperrors.Internal(err)
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/patch"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errPostChanged fails a write whose If-Match or version is behind the stored post
+var errPostChanged = apperrors.PreconditionFailed("Post has been changed since it was read; fetch it again and retry")
+
+// digest is a short hash of a response body
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
+
+// postETag tags a post rendered as body with its version, which If-Match
+// compares, and a digest of body, which changes with its reactions and
+// comment count too
+func postETag(post models.Post, body []byte) string {
+	return `"` + strconv.FormatInt(post.Version, 10) + "-" + digest(body) + `"`
+}
+
+// listETag tags a rendered list of posts by its body alone
+func listETag(body []byte) string {
+	return `"` + digest(body) + `"`
+}
+
+// entityTags splits the comma separated entity tags of an If-Match or
+// If-None-Match header
+func entityTags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// renderTagged responds with obj tagged by the ETag etag computes from its
+// body, or with 304 Not Modified when a GET already holds that tag in
+// If-None-Match. The body differs per caller, so caches must key on the token.
+func renderTagged(c *gin.Context, obj any, etag func(body []byte) string) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	tag := etag(body)
+	c.Header("ETag", tag)
+	c.Header("Vary", "Authorization")
+	if c.Request.Method == http.MethodGet && noneMatch(c.GetHeader("If-None-Match"), tag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// noneMatch reports whether the If-None-Match header lists tag, comparing
+// weakly as RFC 9110 asks
+func noneMatch(header string, tag string) bool {
+	for _, candidate := range entityTags(header) {
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch reports whether the If-Match header lists a tag of the given post
+// version. Only the version part of a tag is compared, so a tag read before
+// a reaction still matches; weak tags never match.
+func ifMatch(header string, version int64) bool {
+	for _, candidate := range entityTags(header) {
+		if candidate == "*" {
+			return true
+		}
+		if !strings.HasPrefix(candidate, `"`) || !strings.HasSuffix(candidate, `"`) || len(candidate) < 2 {
+			continue
+		}
+		tagged, _, _ := strings.Cut(candidate[1:len(candidate)-1], "-")
+		if n, err := strconv.ParseInt(tagged, 10, 64); err == nil && n == version {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVersion makes a write to post state the version it was read at,
+// either in If-Match or as bodyVersion, which is 0 when the body has none.
+// Both must match when both are given.
+func checkVersion(c *gin.Context, post models.Post, bodyVersion int64) error {
+	header := c.GetHeader("If-Match")
+	if header == "" && bodyVersion == 0 {
+		return apperrors.PreconditionRequired("If-Match header or version is required")
+	}
+	if header != "" && !ifMatch(header, post.Version) {
+		return errPostChanged
+	}
+	if bodyVersion != 0 && bodyVersion != post.Version {
+		return errPostChanged
+	}
+	return nil
+}
+
+// patchVersion reads the version a patch document expects: the "version"
+// member of a merge patch, or the value of a JSON Patch test of /version.
+// It is 0 when there is none; malformed documents are reported by applyPatch.
+func patchVersion(contentType string, body []byte) int64 {
+	var version int64
+	switch contentType {
+	case patch.MergePatchType:
+		var members struct {
+			Version *int64 `json:"version"`
+		}
+		if json.Unmarshal(body, &members) == nil && members.Version != nil {
+			version = *members.Version
+		}
+	case patch.JSONPatchType:
+		var operations []struct {
+			Op    string          `json:"op"`
+			Path  string          `json:"path"`
+			Value json.RawMessage `json:"value"`
+		}
+		json.Unmarshal(body, &operations)
+		for _, op := range operations {
+			if op.Op == "test" && op.Path == "/version" {
+				json.Unmarshal(op.Value, &version)
+			}
+		}
+	}
+	return version
+}
+
+// bodyVersion reads the version of the optional models.PostVersion body of a
+// delete, 0 when there is no body
+func bodyVersion(c *gin.Context) (int64, error) {
+	if c.Request.Body == nil {
+		return 0, nil
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		return 0, apperrors.BadRequest("Invalid request body")
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return 0, nil
+	}
+	var expected models.PostVersion
+	if err := json.Unmarshal(body, &expected); err != nil {
+		return 0, apperrors.BadRequest("Invalid request body")
+	}
+	return expected.Version, nil
+}
@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// serveIfMatch sends a bodiless request with etag as If-Match
+func serveIfMatch(router *gin.Engine, method string, path string, etag string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	req.Header.Set("If-Match", etag)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// serveIfNoneMatch sends a GET with etag as If-None-Match
+func serveIfNoneMatch(router *gin.Engine, path string, etag string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	req.Header.Set("If-None-Match", etag)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// putIfMatch replaces the post at path with post, sending etag as If-Match
+func putIfMatch(router *gin.Engine, path string, etag string, post models.Post) *httptest.ResponseRecorder {
+	jsonValue, _ := json.Marshal(post)
+	req, _ := http.NewRequest("PUT", path, bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func newETagRouter(repos *repositories.Repositories, username string) *gin.Engine {
+	postController := newTestPostController(repos)
+	router := newTestRouter()
+	router.Use(withUser(username))
+	router.GET("/posts", postController.GetPosts)
+	router.GET("/posts/:id", postController.GetPost)
+	router.PUT("/posts/:id", postController.UpdatePost)
+	router.PATCH("/posts/:id", postController.PatchPost)
+	router.DELETE("/posts/:id", postController.DeletePost)
+	return router
+}
+
+func TestGetPostETag(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	router := newETagRouter(repos, "alice")
+	path := "/posts/" + post.ID.Hex()
+
+	recorder := serve(router, "GET", path)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	etag := recorder.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `"1-`))
+	assert.Equal(t, "Authorization", recorder.Header().Get("Vary"))
+
+	// A client holding the current representation gets no body
+	recorder = serveIfNoneMatch(router, path, etag)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+	assert.Equal(t, etag, recorder.Header().Get("ETag"))
+	recorder = serveIfNoneMatch(router, path, `"0-stale", W/`+etag)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	// A comment changes the representation but not the version
+	repos.Posts.IncrementCommentCount(context.TODO(), post.ID, 1)
+	recorder = serveIfNoneMatch(router, path, etag)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	commented := recorder.Header().Get("ETag")
+	assert.NotEqual(t, etag, commented)
+	assert.True(t, strings.HasPrefix(commented, `"1-`))
+
+	// so an edit made before it still applies
+	recorder = putIfMatch(router, path, etag, models.Post{Title: "Renamed", Content: post.Content})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	recorder = serveIfNoneMatch(router, path, commented)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, strings.HasPrefix(recorder.Header().Get("ETag"), `"2-`))
+}
+
+func TestGetPostsETag(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	insertTestPost(repos, alice)
+	router := newETagRouter(repos, "alice")
+
+	recorder := serve(router, "GET", "/posts")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	etag := recorder.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	recorder = serveIfNoneMatch(router, "/posts", etag)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	insertTestPost(repos, alice)
+	recorder = serveIfNoneMatch(router, "/posts", etag)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEqual(t, etag, recorder.Header().Get("ETag"))
+}
+
+func TestUpdatePostPreconditions(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	router := newETagRouter(repos, "alice")
+	path := "/posts/" + post.ID.Hex()
+	edit := models.Post{Title: "Renamed", Content: post.Content}
+
+	tests := []struct {
+		name    string
+		etag    string
+		version int64
+		status  int
+	}{
+		{"no precondition", "", 0, http.StatusPreconditionRequired},
+		{"stale tag", `"0-abc"`, 0, http.StatusPreconditionFailed},
+		{"weak tag", `W/"1"`, 0, http.StatusPreconditionFailed},
+		{"stale body version", "", 7, http.StatusPreconditionFailed},
+		{"tag and body disagree", `"1"`, 7, http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edit.Version = tt.version
+			recorder := putIfMatch(router, path, tt.etag, edit)
+			assert.Equal(t, tt.status, recorder.Code)
+			var response models.Response
+			json.Unmarshal(recorder.Body.Bytes(), &response)
+			if tt.status == http.StatusPreconditionRequired {
+				assert.Equal(t, "precondition_required", response.Code)
+			} else {
+				assert.Equal(t, "precondition_failed", response.Code)
+			}
+		})
+	}
+	stored, _ := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.Equal(t, post.Title, stored.Title)
+	assert.Equal(t, int64(1), stored.Version)
+}
+
+func TestConcurrentEdits(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	router := newETagRouter(repos, "alice")
+	path := "/posts/" + post.ID.Hex()
+
+	// Two clients read the same version
+	etag := serve(router, "GET", path).Header().Get("ETag")
+
+	recorder := putIfMatch(router, path, etag, models.Post{Title: "First", Content: post.Content})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	// The second no longer overwrites the first
+	recorder = putIfMatch(router, path, etag, models.Post{Title: "Second", Content: post.Content})
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	recorder = sendPatch(router, path, "application/merge-patch+json", etag, `{"title":"Second"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	recorder = sendPatch(router, path, "application/json-patch+json", "",
+		`[{"op":"test","path":"/version","value":1},{"op":"replace","path":"/title","value":"Second"}]`)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	recorder = serveIfMatch(router, "DELETE", path, etag)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+
+	stored, _ := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.Equal(t, "First", stored.Title)
+	assert.Equal(t, int64(2), stored.Version)
+
+	recorder = serveIfMatch(router, "DELETE", path, "*")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+// staleReads reads posts as they were at version, as if another write got in
+// between a handler's read and its write
+type staleReads struct {
+	repositories.PostRepository
+	version int64
+}
+
+func (r staleReads) FindByID(ctx context.Context, id primitive.ObjectID) (models.Post, error) {
+	post, err := r.PostRepository.FindByID(ctx, id)
+	post.Version = r.version
+	return post, err
+}
+
+func TestWriteLosesRace(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	repos.Posts.Update(context.TODO(), post.ID, 1, post)
+	postController := newTestPostController(repos)
+	postController.Posts = staleReads{repos.Posts, 1}
+	router := newTestRouter()
+	router.Use(withUser("alice"))
+	router.PUT("/posts/:id", postController.UpdatePost)
+	router.DELETE("/posts/:id", postController.DeletePost)
+	path := "/posts/" + post.ID.Hex()
+
+	// The precondition holds when checked but not when written
+	recorder := putIfMatch(router, path, `"1"`, models.Post{Title: "Renamed", Content: post.Content})
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	recorder = serveIfMatch(router, "DELETE", path, `"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+
+	stored, _ := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.Equal(t, post.Title, stored.Title)
+	assert.Equal(t, int64(2), stored.Version)
+}
+
+func TestPatchPostVersion(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	router := newETagRouter(repos, "alice")
+	path := "/posts/" + post.ID.Hex()
+
+	recorder := sendPatch(router, path, "application/merge-patch+json", "", `{"title":"Renamed"}`)
+	assert.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+	recorder = sendPatch(router, path, "application/merge-patch+json", "", `{"title":"Renamed","version":2}`)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+
+	// The version is a precondition, not a change
+	recorder = sendPatch(router, path, "application/json-patch+json", `"1"`, `[{"op":"replace","path":"/version","value":5}]`)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	recorder = sendPatch(router, path, "application/merge-patch+json", "", `{"title":"Renamed","version":1}`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var patched models.Post
+	json.Unmarshal(recorder.Body.Bytes(), &patched)
+	assert.Equal(t, "Renamed", patched.Title)
+	assert.Equal(t, int64(2), patched.Version)
+}
+
+func TestDeletePostPreconditions(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	router := newETagRouter(repos, "alice")
+	path := "/posts/" + post.ID.Hex()
+
+	deleteWithBody := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusPreconditionRequired, serve(router, "DELETE", path).Code)
+	assert.Equal(t, http.StatusPreconditionRequired, deleteWithBody(`{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, deleteWithBody(`{"version":`).Code)
+	assert.Equal(t, http.StatusPreconditionFailed, deleteWithBody(`{"version":2}`).Code)
+	assert.Equal(t, http.StatusPreconditionFailed, serveIfMatch(router, "DELETE", path, `"2-abc"`).Code)
+
+	assert.Equal(t, http.StatusOK, deleteWithBody(`{"version":1}`).Code)
+	_, err := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
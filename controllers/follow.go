@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+	"github.com/VisarutJDev/social-media-api/timeline"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FollowController serves profiles and the follow graph
+type FollowController struct {
+	Follows  repositories.FollowRepository
+	Users    repositories.UserRepository
+	Timeline timeline.Strategy
+}
+
+func NewFollowController(follows repositories.FollowRepository, users repositories.UserRepository, strategy timeline.Strategy) *FollowController {
+	return &FollowController{Follows: follows, Users: users, Timeline: strategy}
+}
+
+// pathUser loads the user named by the :username path parameter
+func (fc *FollowController) pathUser(c *gin.Context) (models.User, error) {
+	user, err := fc.Users.FindByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		return user, lookupError(err, "User not found")
+	}
+	return user, nil
+}
+
+// GetProfile godoc
+//
+//	@Summary		Get Profile
+//	@Description	Get a user's public profile with follower and following counts
+//	@ID				GetProfile
+//	@Tags			follow
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			username	path		string			true	"username"
+//	@Success		200			{object}	models.Profile	"OK"
+//	@Failure		401			{object}	models.Response	"Unauthorized"
+//	@Failure		404			{object}	models.Response	"Not Found"
+//	@Failure		500			{object}	models.Response	"Internal Server Error"
+//	@Router			/users/{username} [get]
+func (fc *FollowController) GetProfile(c *gin.Context) {
+	user, err := fc.pathUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	ctx := c.Request.Context()
+	profile := models.Profile{ID: user.ID, Username: user.Username}
+	if profile.FollowersCount, err = fc.Follows.CountFollowers(ctx, user.ID); err == nil {
+		profile.FollowingCount, err = fc.Follows.CountFollowing(ctx, user.ID)
+	}
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	viewer, err := currentUser(c, fc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if profile.Following, err = fc.Follows.IsFollowing(ctx, viewer.ID, user.ID); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// Follow godoc
+//
+//	@Summary		Follow
+//	@Description	Follow a user. Following a user twice has no effect.
+//	@ID				Follow
+//	@Tags			follow
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			username	path		string			true	"username to follow"
+//	@Success		200			{object}	models.Response	"OK"
+//	@Failure		400			{object}	models.Response	"Bad Request"
+//	@Failure		401			{object}	models.Response	"Unauthorized"
+//	@Failure		404			{object}	models.Response	"Not Found"
+//	@Failure		500			{object}	models.Response	"Internal Server Error"
+//	@Router			/users/{username}/follow [post]
+func (fc *FollowController) Follow(c *gin.Context) {
+	follower, err := currentUser(c, fc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	followee, err := fc.pathUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if follower.ID == followee.ID {
+		c.Error(apperrors.BadRequest("You cannot follow yourself"))
+		return
+	}
+
+	err = fc.Follows.Follow(c.Request.Context(), models.Follow{
+		ID:         primitive.NewObjectID(),
+		FollowerID: follower.ID,
+		Follower:   follower.Username,
+		FolloweeID: followee.ID,
+		Followee:   followee.Username,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Followed " + followee.Username,
+	})
+}
+
+// Unfollow godoc
+//
+//	@Summary		Unfollow
+//	@Description	Stop following a user; their posts leave the home timeline
+//	@ID				Unfollow
+//	@Tags			follow
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			username	path		string			true	"username to unfollow"
+//	@Success		200			{object}	models.Response	"OK"
+//	@Failure		401			{object}	models.Response	"Unauthorized"
+//	@Failure		404			{object}	models.Response	"Not Found"
+//	@Failure		500			{object}	models.Response	"Internal Server Error"
+//	@Router			/users/{username}/follow [delete]
+func (fc *FollowController) Unfollow(c *gin.Context) {
+	follower, err := currentUser(c, fc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	followee, err := fc.pathUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = fc.Follows.Unfollow(c.Request.Context(), follower.ID, followee.ID)
+	if err != nil {
+		c.Error(lookupError(err, "You are not following "+followee.Username))
+		return
+	}
+	// The follow is gone either way; a stored timeline keeps their posts until a rebuild
+	if err := fc.Timeline.Unfollowed(c.Request.Context(), follower.ID, followee.ID); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to remove posts from timeline", "follower_id", follower.ID.Hex(), "followee_id", followee.ID.Hex(), "error", err)
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Unfollowed " + followee.Username,
+	})
+}
+
+// GetFollowers godoc
+//
+//	@Summary		Get Followers
+//	@Description	Get the users following a user, most recent first
+//	@ID				GetFollowers
+//	@Tags			follow
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			username	path		string				true	"username"
+//	@Param			limit		query		int					false	"page size, at most 100"	default(20)
+//	@Param			cursor		query		string				false	"next_cursor or prev_cursor from a previous page"
+//	@Success		200			{object}	models.FollowPage	"OK"
+//	@Failure		400			{object}	models.Response		"Bad Request"
+//	@Failure		401			{object}	models.Response		"Unauthorized"
+//	@Failure		404			{object}	models.Response		"Not Found"
+//	@Failure		500			{object}	models.Response		"Internal Server Error"
+//	@Router			/users/{username}/followers [get]
+func (fc *FollowController) GetFollowers(c *gin.Context) {
+	fc.listFollows(c, fc.Follows.Followers)
+}
+
+// GetFollowing godoc
+//
+//	@Summary		Get Following
+//	@Description	Get the users a user follows, most recent first
+//	@ID				GetFollowing
+//	@Tags			follow
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			username	path		string				true	"username"
+//	@Param			limit		query		int					false	"page size, at most 100"	default(20)
+//	@Param			cursor		query		string				false	"next_cursor or prev_cursor from a previous page"
+//	@Success		200			{object}	models.FollowPage	"OK"
+//	@Failure		400			{object}	models.Response		"Bad Request"
+//	@Failure		401			{object}	models.Response		"Unauthorized"
+//	@Failure		404			{object}	models.Response		"Not Found"
+//	@Failure		500			{object}	models.Response		"Internal Server Error"
+//	@Router			/users/{username}/following [get]
+func (fc *FollowController) GetFollowing(c *gin.Context) {
+	fc.listFollows(c, fc.Follows.Following)
+}
+
+type listFollowsFunc func(ctx context.Context, userID primitive.ObjectID, page repositories.Page) ([]models.Follow, error)
+
+func (fc *FollowController) listFollows(c *gin.Context, list listFollowsFunc) {
+	page, err := parsePage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	user, err := fc.pathUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	follows, err := list(c.Request.Context(), user.ID, fetchPage(page))
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	follows, hasMore := trimPage(page, follows)
+	var first, last primitive.ObjectID
+	if len(follows) > 0 {
+		first, last = follows[0].ID, follows[len(follows)-1].ID
+	}
+	c.JSON(http.StatusOK, models.FollowPage{
+		Data:       follows,
+		Pagination: newPagination(page, hasMore, first, last),
+	})
+}
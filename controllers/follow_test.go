@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+	"github.com/VisarutJDev/social-media-api/timeline"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFollowRouter serves the follow routes acting as username
+func newFollowRouter(repos *repositories.Repositories, username string) *gin.Engine {
+	followController := NewFollowController(repos.Follows, repos.Users, timeline.NewFanOutOnRead(repos.Posts, repos.Follows))
+
+	router := newTestRouter()
+	router.Use(withUser(username))
+	router.GET("/users/:username", followController.GetProfile)
+	router.POST("/users/:username/follow", followController.Follow)
+	router.DELETE("/users/:username/follow", followController.Unfollow)
+	router.GET("/users/:username/followers", followController.GetFollowers)
+	router.GET("/users/:username/following", followController.GetFollowing)
+	return router
+}
+
+func serve(router *gin.Engine, method string, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestFollow(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "alice", models.RoleUser)
+	insertTestUser(repos, "bob", models.RoleUser)
+	router := newFollowRouter(repos, "alice")
+
+	recorder := serve(router, "POST", "/users/bob/follow")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Following twice keeps a single edge
+	recorder = serve(router, "POST", "/users/bob/follow")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = serve(router, "GET", "/users/bob")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var profile models.Profile
+	err := json.Unmarshal(recorder.Body.Bytes(), &profile)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", profile.Username)
+	assert.Equal(t, int64(1), profile.FollowersCount)
+	assert.Equal(t, int64(0), profile.FollowingCount)
+	assert.True(t, profile.Following)
+
+	recorder = serve(router, "GET", "/users/alice/following")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var page models.FollowPage
+	err = json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.NoError(t, err)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "bob", page.Data[0].Followee)
+}
+
+func TestFollowYourself(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "alice", models.RoleUser)
+	router := newFollowRouter(repos, "alice")
+
+	recorder := serve(router, "POST", "/users/alice/follow")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestFollowUnknownUser(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "alice", models.RoleUser)
+	router := newFollowRouter(repos, "alice")
+
+	recorder := serve(router, "POST", "/users/nobody/follow")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestUnfollow(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "alice", models.RoleUser)
+	insertTestUser(repos, "bob", models.RoleUser)
+	router := newFollowRouter(repos, "alice")
+
+	serve(router, "POST", "/users/bob/follow")
+	recorder := serve(router, "DELETE", "/users/bob/follow")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// There is nothing left to unfollow
+	recorder = serve(router, "DELETE", "/users/bob/follow")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestGetFollowersPagination(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "bob", models.RoleUser)
+	for _, username := range []string{"alice", "carol", "dave"} {
+		insertTestUser(repos, username, models.RoleUser)
+		serve(newFollowRouter(repos, username), "POST", "/users/bob/follow")
+	}
+	router := newFollowRouter(repos, "bob")
+
+	recorder := serve(router, "GET", "/users/bob/followers?limit=2")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var page models.FollowPage
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, "dave", page.Data[0].Follower)
+	assert.Equal(t, "carol", page.Data[1].Follower)
+	assert.True(t, page.Pagination.HasMore)
+
+	recorder = serve(router, "GET", "/users/bob/followers?limit=2&cursor="+page.Pagination.NextCursor)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "alice", page.Data[0].Follower)
+	assert.False(t, page.Pagination.HasMore)
+}
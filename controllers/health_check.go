@@ -1,19 +1,97 @@
 package controllers
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Dependency is something the service needs to serve traffic
+type Dependency struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+// HealthController serves the liveness and readiness probes
+type HealthController struct {
+	Dependencies []Dependency
+	Timeout      time.Duration // Limit on each dependency check
+}
+
+func NewHealthController(timeout time.Duration, dependencies ...Dependency) *HealthController {
+	return &HealthController{Dependencies: dependencies, Timeout: timeout}
+}
+
 // HealthCheckHandler godoc
 //
-//	@Summary		Health Check
-//	@Description	Health checking for the service
+//	@Summary		Liveness
+//	@Description	Reports that the process is alive. It does not check dependencies; see /readyz.
 //	@ID				HealthCheckHandler
+//	@Tags			health
 //	@Produce		plain
 //	@Success		200	{string}	string	"OK"
-//	@Router			/healthcheck [get]
+//	@Router			/healthz [get]
 func HealthCheckHandler(c *gin.Context) {
 	c.String(http.StatusOK, "OK")
 }
+
+// Readiness godoc
+//
+//	@Summary		Readiness
+//	@Description	Checks every dependency concurrently and reports 503 when any is down
+//	@ID				Readiness
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	models.Readiness	"OK"
+//	@Failure		503	{object}	models.Readiness	"Service Unavailable"
+//	@Router			/readyz [get]
+func (hc *HealthController) Readiness(c *gin.Context) {
+	readiness := models.Readiness{
+		Status:       models.StatusUp,
+		Dependencies: make(map[string]models.DependencyStatus, len(hc.Dependencies)),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, dependency := range hc.Dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := hc.check(c.Request.Context(), dependency)
+			mu.Lock()
+			defer mu.Unlock()
+			readiness.Dependencies[dependency.Name] = status
+			if status.Status != models.StatusUp {
+				readiness.Status = models.StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	code := http.StatusOK
+	if readiness.Status != models.StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(code, readiness)
+}
+
+func (hc *HealthController) check(ctx context.Context, dependency Dependency) models.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, hc.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := dependency.Ping(ctx)
+	status := models.DependencyStatus{
+		Status:    models.StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = models.StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHealthRouter(health *HealthController) *gin.Engine {
+	router := newTestRouter()
+	router.GET("/healthz", HealthCheckHandler)
+	router.GET("/readyz", health.Readiness)
+	return router
+}
+
+func TestReadiness(t *testing.T) {
+	up := Dependency{Name: "mongo", Ping: func(ctx context.Context) error { return nil }}
+	down := Dependency{Name: "cache", Ping: func(ctx context.Context) error { return errors.New("connection refused") }}
+	slow := Dependency{Name: "search", Ping: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	t.Run("all up", func(t *testing.T) {
+		w := serve(newHealthRouter(NewHealthController(time.Second, up)), "GET", "/readyz")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var readiness models.Readiness
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &readiness))
+		assert.Equal(t, models.StatusUp, readiness.Status)
+		assert.Equal(t, models.StatusUp, readiness.Dependencies["mongo"].Status)
+	})
+
+	t.Run("one down", func(t *testing.T) {
+		w := serve(newHealthRouter(NewHealthController(time.Second, up, down)), "GET", "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		var readiness models.Readiness
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &readiness))
+		assert.Equal(t, models.StatusDown, readiness.Status)
+		assert.Equal(t, models.StatusUp, readiness.Dependencies["mongo"].Status)
+		assert.Equal(t, models.StatusDown, readiness.Dependencies["cache"].Status)
+		assert.Equal(t, "connection refused", readiness.Dependencies["cache"].Error)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		w := serve(newHealthRouter(NewHealthController(10*time.Millisecond, slow)), "GET", "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		var readiness models.Readiness
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &readiness))
+		assert.Equal(t, models.StatusDown, readiness.Dependencies["search"].Status)
+		assert.GreaterOrEqual(t, readiness.Dependencies["search"].LatencyMs, float64(10))
+	})
+
+	t.Run("liveness ignores dependencies", func(t *testing.T) {
+		w := serve(newHealthRouter(NewHealthController(time.Second, down)), "GET", "/healthz")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
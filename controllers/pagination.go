@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var errInvalidCursor = apperrors.BadRequest("Invalid cursor")
+
+// pageCursor is the decoded form of the opaque cursor handed to clients.
+// Items are sorted by _id descending, or by Sort and then _id, so Prev walks
+// towards newer items.
+type pageCursor struct {
+	ID   primitive.ObjectID `json:"id"`
+	Prev bool               `json:"prev,omitempty"`
+	Sort string             `json:"sort,omitempty"`
+	At   *time.Time         `json:"at,omitempty"` // Sort of the item at ID
+}
+
+func encodeCursor(cursor pageCursor) string {
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	var cursor pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+	if err := json.Unmarshal(b, &cursor); err != nil || cursor.ID.IsZero() {
+		return cursor, errInvalidCursor
+	}
+	return cursor, nil
+}
+
+// parsePage reads the limit and cursor query parameters, clamping limit to MaxPageSize
+func parsePage(c *gin.Context) (repositories.Page, error) {
+	return parseSortedPage(c, nil)
+}
+
+// parseSortedPage is parsePage that also reads the sort query parameter, one of
+// sorts or empty for newest first. Cursors only continue the sort they came from.
+func parseSortedPage(c *gin.Context, sorts []string) (repositories.Page, error) {
+	var sort string
+	if sorts != nil {
+		sort = c.Query("sort")
+		if sort != "" && !slices.Contains(sorts, sort) {
+			return repositories.Page{}, apperrors.BadRequest("Invalid sort, want one of " + strings.Join(sorts, ", "))
+		}
+	}
+	return parsePageBy(c, sort)
+}
+
+// parsePageBy is parsePage for lists always sorted by the time field sort
+func parsePageBy(c *gin.Context, sort string) (repositories.Page, error) {
+	page := repositories.Page{Limit: DefaultPageSize, SortBy: sort}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return page, apperrors.BadRequest("Invalid limit")
+		}
+		page.Limit = min(limit, MaxPageSize)
+	}
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeCursor(raw)
+		if err != nil {
+			return page, err
+		}
+		if cursor.Sort != page.SortBy || (cursor.Sort != "" && cursor.At == nil) {
+			return page, errInvalidCursor
+		}
+		page.Cursor = cursor.ID
+		page.Prev = cursor.Prev
+		if cursor.At != nil {
+			page.CursorAt = *cursor.At
+		}
+	}
+	return page, nil
+}
+
+// fetchPage returns page with room for one extra item, which tells whether there is another page
+func fetchPage(page repositories.Page) repositories.Page {
+	page.Limit++
+	return page
+}
+
+// trimPage drops the extra item requested by fetchPage. Walking backwards it is the newest one.
+func trimPage[T any](page repositories.Page, items []T) ([]T, bool) {
+	if len(items) <= page.Limit {
+		return items, false
+	}
+	if page.Prev {
+		return items[len(items)-page.Limit:], true
+	}
+	return items[:page.Limit], true
+}
+
+// newPagination builds the envelope for a page whose items, newest first, run from first to last
+func newPagination(page repositories.Page, hasMore bool, first, last primitive.ObjectID) models.Pagination {
+	return newSortedPagination(page, hasMore, pageCursor{ID: first}, pageCursor{ID: last})
+}
+
+// newSortedPagination is newPagination for pages sorted by page.SortBy, where
+// first and last also hold the time of their items
+func newSortedPagination(page repositories.Page, hasMore bool, first, last pageCursor) models.Pagination {
+	pagination := models.Pagination{Limit: page.Limit, HasMore: hasMore}
+	if first.ID.IsZero() {
+		return pagination
+	}
+	first.Sort, last.Sort = page.SortBy, page.SortBy
+	hasCursor := !page.Cursor.IsZero()
+	if hasMore || page.Prev {
+		pagination.NextCursor = encodeCursor(last)
+	}
+	if hasCursor && (hasMore || !page.Prev) {
+		first.Prev = true
+		pagination.PrevCursor = encodeCursor(first)
+	}
+	return pagination
+}
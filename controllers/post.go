@@ -1,18 +1,103 @@
 package controllers
 
 import (
-	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"reflect"
+	"slices"
+	"time"
 
-	"github.com/VisarutJDev/social-media-api/database"
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/metrics"
 	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/patch"
+	"github.com/VisarutJDev/social-media-api/repositories"
+	"github.com/VisarutJDev/social-media-api/timeline"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/gin-gonic/gin/binding"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// PostController serves the post endpoints
+type PostController struct {
+	Posts     repositories.PostRepository
+	Comments  repositories.CommentRepository
+	Reactions repositories.ReactionRepository
+	Revisions repositories.RevisionRepository
+	Users     repositories.UserRepository
+	Timeline  timeline.Strategy
+	Retention time.Duration // How long deleted posts can be restored from the trash
+}
+
+func NewPostController(posts repositories.PostRepository, comments repositories.CommentRepository, reactions repositories.ReactionRepository, revisions repositories.RevisionRepository, users repositories.UserRepository, strategy timeline.Strategy, retention time.Duration) *PostController {
+	return &PostController{Posts: posts, Comments: comments, Reactions: reactions, Revisions: revisions, Users: users, Timeline: strategy, Retention: retention}
+}
+
+// currentUser loads the user behind the "username" set by middlewares.AuthMiddleware
+func currentUser(c *gin.Context, users repositories.UserRepository) (models.User, error) {
+	user, err := users.FindByUsername(c.Request.Context(), c.GetString("username"))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return user, apperrors.Unauthorized("User not found")
+	}
+	if err != nil {
+		return user, apperrors.Internal(err)
+	}
+	return user, nil
+}
+
+// pathPost loads the post named by the :id path parameter
+func pathPost(c *gin.Context, posts repositories.PostRepository) (models.Post, error) {
+	objID, err := pathID(c, "id", "post")
+	if err != nil {
+		return models.Post{}, err
+	}
+	post, err := posts.FindByID(c.Request.Context(), objID)
+	if err != nil {
+		return post, lookupError(err, "Post not found")
+	}
+	return post, nil
+}
+
+// postCursor points a page cursor at post, holding its time named by sort
+func postCursor(post models.Post, sort string) pageCursor {
+	cursor := pageCursor{ID: post.ID}
+	if at, ok := post.SortTime(sort); ok {
+		cursor.At = &at
+	}
+	return cursor
+}
+
+// canModifyPost reports whether user owns post or is an admin. Posts written
+// before author_id was stored are owned by the user named as their author.
+func canModifyPost(user models.User, post models.Post) bool {
+	if user.EffectiveRole() == models.RoleAdmin {
+		return true
+	}
+	if post.AuthorID.IsZero() {
+		return post.Author == user.Username
+	}
+	return post.AuthorID == user.ID
+}
+
+// setViewerReactions marks the caller's reactions on posts. Posts are left
+// unmarked when the caller is unknown.
+func (pc *PostController) setViewerReactions(c *gin.Context, posts []models.Post) error {
+	user, err := pc.Users.FindByUsername(c.Request.Context(), c.GetString("username"))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err == nil {
+		err = setViewerReactions(c.Request.Context(), pc.Reactions, user.ID, posts)
+	}
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
 // CreatePost     godoc
 //
 //	@Summary		Create Post
@@ -23,65 +108,95 @@ import (
 //	@Accept			json
 //	@Produce		json
 //	@Param			post	body		models.Post		true	"Post data to be Created"
-//	@Success		200		{object}	models.Post		"OK"
+//	@Success		201		{object}	models.Post		"Created"
 //	@Failure		400		{object}	models.Response	"Bad Request"
 //	@Failure		401		{object}	models.Response	"Unauthorized"
+//	@Failure		422		{object}	models.Response	"Unprocessable Entity"
 //	@Failure		500		{object}	models.Response	"Internal Server Error"
 //	@Router			/posts [post]
-func CreatePost(c *gin.Context) {
+func (pc *PostController) CreatePost(c *gin.Context) {
 	var post models.Post
-	if err := c.ShouldBindJSON(&post); err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Error: err.Error(),
-		})
-		// c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &post); err != nil {
+		c.Error(err)
+		return
+	}
+	user, err := currentUser(c, pc.Users)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 	post.ID = primitive.NewObjectID()
-	_, err := database.Client.Database("social_media").Collection("posts").InsertOne(context.Background(), post)
+	post.AuthorID = user.ID
+	post.Author = user.Username
+	post.CommentCount = 0
+	post.Reactions = nil
+	post.CreatedAt = models.Now()
+	post.UpdatedAt = post.CreatedAt
+	post.EditedAt = nil
+	post.DeletedAt = nil
+	post.DeletedBy = ""
+	post.Version = 1
+	err = pc.Posts.Create(c.Request.Context(), post)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: err.Error(),
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
+	metrics.PostsCreated.Inc()
+	if err := pc.Revisions.Create(c.Request.Context(), newRevision(models.Post{}, post, 1, user)); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to record revision", "post_id", post.ID.Hex(), "error", err)
+	}
+	// The post is stored either way; timelines only miss it until a rebuild
+	if err := pc.Timeline.PostCreated(c.Request.Context(), post); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to add post to timelines", "post_id", post.ID.Hex(), "error", err)
+	}
 	c.JSON(http.StatusCreated, post)
 }
 
 // GetPosts godoc
 //
 //	@Summary		Get Posts
-//	@Description	Get posts
+//	@Description	Get posts newest first, or most recently created, updated or edited first, paginated with an opaque cursor
 //	@ID				GetPosts
 //	@Tags			post
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	models.Post		"OK"
-//	@Failure		400	{object}	models.Response	"Bad Request"
-//	@Failure		401	{object}	models.Response	"Unauthorized"
-//	@Failure		500	{object}	models.Response	"Internal Server Error"
+//	@Param			limit	query		int				false	"page size, at most 100"	default(20)
+//	@Param			cursor	query		string			false	"next_cursor or prev_cursor from a previous page"
+//	@Param			sort			query		string			false	"time to sort by, newest first; edited_at lists only edited posts"	Enums(created_at, updated_at, edited_at)
+//	@Param			If-None-Match	header		string			false	"ETag of a page fetched before"
+//	@Success		200				{object}	models.PostPage	"OK"
+//	@Header			200				{string}	ETag			"tag of the page"
+//	@Success		304				"Not Modified"
+//	@Failure		400				{object}	models.Response	"Bad Request"
+//	@Failure		401		{object}	models.Response	"Unauthorized"
+//	@Failure		500		{object}	models.Response	"Internal Server Error"
 //	@Router			/posts [get]
-func GetPosts(c *gin.Context) {
-	findOptions := options.Find()
-	// Sort by `price` field descending
-	findOptions.SetSort(bson.D{{Key: "_id", Value: -1}})
-
-	cursor, err := database.Client.Database("social_media").Collection("posts").Find(context.Background(), bson.M{}, findOptions)
+func (pc *PostController) GetPosts(c *gin.Context) {
+	page, err := parseSortedPage(c, models.PostSorts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: err.Error(),
-		})
+		c.Error(err)
 		return
 	}
-	var posts []models.Post
-	if err = cursor.All(context.Background(), &posts); err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: err.Error(),
-		})
+	posts, err := pc.Posts.List(c.Request.Context(), fetchPage(page))
+	if err != nil {
+		c.Error(apperrors.Internal(err))
 		return
 	}
-	c.JSON(http.StatusOK, posts)
+
+	posts, hasMore := trimPage(page, posts)
+	if err := pc.setViewerReactions(c, posts); err != nil {
+		c.Error(err)
+		return
+	}
+	var first, last pageCursor
+	if len(posts) > 0 {
+		first, last = postCursor(posts[0], page.SortBy), postCursor(posts[len(posts)-1], page.SortBy)
+	}
+	renderTagged(c, models.PostPage{
+		Data:       posts,
+		Pagination: newSortedPagination(page, hasMore, first, last),
+	}, listETag)
 }
 
 // GetPost godoc
@@ -91,98 +206,338 @@ func GetPosts(c *gin.Context) {
 //	@ID				GetPost
 //	@Tags			post
 //	@Security		Bearer
-//	@iD				GetPost
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string			true	"id of post to be get"
-//	@Success		200	{object}	models.Post		"OK"
-//	@Failure		400	{object}	models.Response	"Bad Request"
-//	@Failure		401	{object}	models.Response	"Unauthorized"
-//	@Failure		500	{object}	models.Response	"Internal Server Error"
+//	@Param			id				path		string			true	"id of post to be get"
+//	@Param			If-None-Match	header		string			false	"ETag of the post fetched before"
+//	@Success		200				{object}	models.Post		"OK"
+//	@Header			200				{string}	ETag			"tag of the post; its version can be sent back in If-Match"
+//	@Success		304				"Not Modified"
+//	@Failure		400				{object}	models.Response	"Bad Request"
+//	@Failure		401				{object}	models.Response	"Unauthorized"
+//	@Failure		404				{object}	models.Response	"Not Found"
+//	@Failure		500				{object}	models.Response	"Internal Server Error"
 //	@Router			/posts/{id} [get]
-func GetPost(c *gin.Context) {
-	id := c.Param("id")
-	objID, _ := primitive.ObjectIDFromHex(id)
-	var post models.Post
-	err := database.Client.Database("social_media").Collection("posts").FindOne(context.Background(), bson.M{"_id": objID}).Decode(&post)
+func (pc *PostController) GetPost(c *gin.Context) {
+	post, err := pathPost(c, pc.Posts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: "Post not found",
-		})
-		// c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, post)
+	pc.renderPost(c, post)
 }
 
 // UpdatePost godoc
 //
 //	@Summary		Update Post
-//	@Description	Update post by id
+//	@Description	Replace the title and content of post by id; both are required. Use PATCH to change only some of them. The post must still be at the version given in If-Match or in the body.
 //	@ID				UpdatePost
 //	@Tags			post
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		string			true	"id of post to be updated"
-//	@Param			post	body		models.Post		true	"Post data to be updated"
-//	@Success		200		{object}	models.Response	"OK"
-//	@Failure		400		{object}	models.Response	"Bad Request"
-//	@Failure		401		{object}	models.Response	"Unauthorized"
-//	@Failure		500		{object}	models.Response	"Internal Server Error"
+//	@Param			id			path		string			true	"id of post to be updated"
+//	@Param			If-Match	header		string			false	"ETag of the post being replaced; required unless the body has its version"
+//	@Param			post		body		models.Post		true	"Post data to be updated"
+//	@Success		200			{object}	models.Post		"OK"
+//	@Header			200			{string}	ETag			"tag of the updated post"
+//	@Failure		400			{object}	models.Response	"Bad Request"
+//	@Failure		401			{object}	models.Response	"Unauthorized"
+//	@Failure		403			{object}	models.Response	"Forbidden"
+//	@Failure		404			{object}	models.Response	"Not Found"
+//	@Failure		412			{object}	models.Response	"Precondition Failed"
+//	@Failure		422			{object}	models.Response	"Unprocessable Entity"
+//	@Failure		428			{object}	models.Response	"Precondition Required"
+//	@Failure		500			{object}	models.Response	"Internal Server Error"
 //	@Router			/posts/{id} [put]
-func UpdatePost(c *gin.Context) {
-	id := c.Param("id")
-	objID, _ := primitive.ObjectIDFromHex(id)
+func (pc *PostController) UpdatePost(c *gin.Context) {
+	objID, err := pathID(c, "id", "post")
+	if err != nil {
+		c.Error(err)
+		return
+	}
 	var post models.Post
-	if err := c.ShouldBindJSON(&post); err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: err.Error(),
-		})
-		// c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &post); err != nil {
+		c.Error(err)
 		return
 	}
-	_, err := database.Client.Database("social_media").Collection("posts").UpdateOne(context.Background(), bson.M{"_id": objID}, bson.M{"$set": post})
+	user, err := currentUser(c, pc.Users)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: err.Error(),
-		})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, models.Response{
-		Message: "Post updated successfully",
+	existing, err := pc.Posts.FindByID(c.Request.Context(), objID)
+	if err != nil {
+		c.Error(lookupError(err, "Post not found"))
+		return
+	}
+	if !canModifyPost(user, existing) {
+		c.Error(apperrors.Forbidden("You are not allowed to update this post"))
+		return
+	}
+	if err := checkVersion(c, existing, post.Version); err != nil {
+		c.Error(err)
+		return
+	}
+	// Only the title and content come from the client
+	updated, err := pc.edit(c, user, existing, post.Title, post.Content)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	pc.renderPost(c, updated)
+}
+
+// PatchPost godoc
+//
+//	@Summary		Patch Post
+//	@Description	Change some fields of post by id with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) of its JSON form. Only the title and content can change, and the result is validated like a PUT. The post must still be at the version given in If-Match, in the version member of a merge patch or in a JSON Patch test of /version.
+//	@ID				PatchPost
+//	@Tags			post
+//	@Security		Bearer
+//	@Accept			application/merge-patch+json,application/json-patch+json
+//	@Produce		json
+//	@Param			id			path		string			true	"id of post to be patched"
+//	@Param			If-Match	header		string			false	"ETag of the post being patched; required unless the patch names its version"
+//	@Param			patch		body		object			true	"Merge patch object, or JSON Patch array of operations"
+//	@Success		200			{object}	models.Post		"OK"
+//	@Header			200			{string}	ETag			"tag of the patched post"
+//	@Failure		400			{object}	models.Response	"Bad Request"
+//	@Failure		401			{object}	models.Response	"Unauthorized"
+//	@Failure		403			{object}	models.Response	"Forbidden"
+//	@Failure		404			{object}	models.Response	"Not Found"
+//	@Failure		409			{object}	models.Response	"Conflict"
+//	@Failure		412			{object}	models.Response	"Precondition Failed"
+//	@Failure		415			{object}	models.Response	"Unsupported Media Type"
+//	@Failure		422			{object}	models.Response	"Unprocessable Entity"
+//	@Failure		428			{object}	models.Response	"Precondition Required"
+//	@Failure		500			{object}	models.Response	"Internal Server Error"
+//	@Router			/posts/{id} [patch]
+func (pc *PostController) PatchPost(c *gin.Context) {
+	apply, ok := patchers[c.ContentType()]
+	if !ok {
+		c.Header("Accept-Patch", patch.MergePatchType+", "+patch.JSONPatchType)
+		c.Error(apperrors.UnsupportedMediaType("Content-Type must be " + patch.MergePatchType + " or " + patch.JSONPatchType))
+		return
+	}
+	user, err := currentUser(c, pc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	existing, err := pathPost(c, pc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !canModifyPost(user, existing) {
+		c.Error(apperrors.Forbidden("You are not allowed to update this post"))
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid request body"))
+		return
+	}
+	if err := checkVersion(c, existing, patchVersion(c.ContentType(), body)); err != nil {
+		c.Error(err)
+		return
+	}
+	patched, err := applyPatch(apply, existing, body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	updated, err := pc.edit(c, user, existing, patched.Title, patched.Content)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	pc.renderPost(c, updated)
+}
+
+// patchers apply the patch documents PatchPost reads, by media type
+var patchers = map[string]func(doc []byte, changes []byte) ([]byte, error){
+	patch.MergePatchType: patch.Merge,
+	patch.JSONPatchType:  patch.Apply,
+}
+
+// editableFields are the members of a post's JSON form that clients may change
+var editableFields = []string{"title", "content"}
+
+// applyPatch applies the patch document body with apply to the JSON form of
+// post and returns post with the patched title and content, validated like
+// UpdatePost. Changing any other member is a validation failure.
+func applyPatch(apply func(doc []byte, changes []byte) ([]byte, error), post models.Post, body []byte) (models.Post, error) {
+	doc, err := json.Marshal(post)
+	if err != nil {
+		return post, apperrors.Internal(err)
+	}
+	patched, err := apply(doc, body)
+	var patchErr *patch.Error
+	if errors.As(err, &patchErr) {
+		if errors.Is(err, patch.ErrConflict) {
+			return post, apperrors.Conflict("Patch does not apply: " + patchErr.Detail)
+		}
+		return post, apperrors.BadRequest("Invalid patch: " + patchErr.Detail)
+	}
+	if err != nil {
+		return post, apperrors.Internal(err)
+	}
+	var before, after map[string]any
+	if err := json.Unmarshal(doc, &before); err != nil {
+		return post, apperrors.Internal(err)
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return post, apperrors.BadRequest("Invalid patch: the result is not a JSON object")
+	}
+
+	fields := []models.FieldError{}
+	names := []string{}
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if slices.Contains(editableFields, name) {
+			continue
+		}
+		from, wasSet := before[name]
+		to, isSet := after[name]
+		if wasSet != isSet || !reflect.DeepEqual(from, to) {
+			fields = append(fields, models.FieldError{Field: name, Code: models.CodeReadOnly, Message: "cannot be changed"})
+		}
+	}
+	for _, name := range editableFields {
+		if value, isSet := after[name]; isSet {
+			if _, ok := value.(string); !ok {
+				fields = append(fields, models.FieldError{Field: name, Code: models.CodeInvalid, Message: "must be a string"})
+			}
+		}
+	}
+	post.Title, _ = after["title"].(string)
+	post.Content, _ = after["content"].(string)
+	if invalid, ok := fieldErrors(binding.Validator.ValidateStruct(post)); ok {
+		for _, field := range invalid {
+			// A field that is not a string is only reported as such
+			if !slices.ContainsFunc(fields, func(f models.FieldError) bool { return f.Field == field.Field }) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	if len(fields) > 0 {
+		return post, apperrors.Validation(fields...)
+	}
+	return post, nil
+}
+
+// edit sets the title and content of existing on behalf of user and returns
+// the stored post, failing its precondition if another write got in since
+// existing was read. Only a change of either marks the post edited and
+// records a revision.
+func (pc *PostController) edit(c *gin.Context, user models.User, existing models.Post, title string, content string) (models.Post, error) {
+	post := existing
+	post.Title = title
+	post.Content = content
+	post.UpdatedAt = models.Now()
+	edited := title != existing.Title || content != existing.Content
+	if edited {
+		post.EditedAt = &post.UpdatedAt
+	}
+	post.Version = existing.Version + 1
+	if err := pc.Posts.Update(c.Request.Context(), post.ID, existing.Version, post); err != nil {
+		// The post may have been changed or deleted since it was read
+		return post, postWriteError(err)
+	}
+	if edited {
+		pc.recordRevision(c, user, existing, post, 0)
+	}
+	return post, nil
+}
+
+// postWriteError reports err from writing a post that a conditional request
+// read: a version conflict means another write got in first
+func postWriteError(err error) error {
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return errPostChanged
+	}
+	return lookupError(err, "Post not found")
+}
+
+// renderPost responds with post, marked with the caller's reaction and
+// tagged with an ETag whose version If-Match can send back
+func (pc *PostController) renderPost(c *gin.Context, post models.Post) {
+	posts := []models.Post{post}
+	if err := pc.setViewerReactions(c, posts); err != nil {
+		c.Error(err)
+		return
+	}
+	renderTagged(c, posts[0], func(body []byte) string {
+		return postETag(posts[0], body)
 	})
-	// c.JSON(http.StatusOK, gin.H{"message": "Post updated successfully"})
 }
 
 // DeletePost godoc
 //
 //	@Summary		Delete Post
-//	@Description	Delete post by id
+//	@Description	Move post to the trash, where its author can restore it until the retention period ends. The post must still be at the version given in If-Match or in the body.
 //	@Tags			post
 //	@Security		Bearer
 //	@ID				DeletePost
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string			true	"id of post to be deleted"
-//	@Success		200	{object}	models.Response	"OK"
-//	@Failure		400	{object}	models.Response	"Bad Request"
-//	@Failure		401	{object}	models.Response	"Unauthorized"
-//	@Failure		500	{object}	models.Response	"Internal Server Error"
+//	@Param			id			path		string				true	"id of post to be deleted"
+//	@Param			If-Match	header		string				false	"ETag of the post being deleted; required unless the body has its version"
+//	@Param			version		body		models.PostVersion	false	"version of the post being deleted"
+//	@Success		200			{object}	models.Response		"OK"
+//	@Failure		400			{object}	models.Response		"Bad Request"
+//	@Failure		401			{object}	models.Response		"Unauthorized"
+//	@Failure		403			{object}	models.Response		"Forbidden"
+//	@Failure		404			{object}	models.Response		"Not Found"
+//	@Failure		412			{object}	models.Response		"Precondition Failed"
+//	@Failure		428			{object}	models.Response		"Precondition Required"
+//	@Failure		500			{object}	models.Response		"Internal Server Error"
 //	@Router			/posts/{id} [delete]
-func DeletePost(c *gin.Context) {
-	id := c.Param("id")
-	objID, _ := primitive.ObjectIDFromHex(id)
-	_, err := database.Client.Database("social_media").Collection("posts").DeleteOne(context.Background(), bson.M{"_id": objID})
+func (pc *PostController) DeletePost(c *gin.Context) {
+	objID, err := pathID(c, "id", "post")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	version, err := bodyVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	user, err := currentUser(c, pc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	existing, err := pc.Posts.FindByID(c.Request.Context(), objID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: err.Error(),
-		})
-		// c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(lookupError(err, "Post not found"))
+		return
+	}
+	if !canModifyPost(user, existing) {
+		c.Error(apperrors.Forbidden("You are not allowed to delete this post"))
+		return
+	}
+	if err := checkVersion(c, existing, version); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := pc.moveToTrash(c, existing, user); err != nil {
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, models.Response{
-		Message: "Post deleted successfully",
+		Message: "Post moved to trash",
 	})
 	// c.JSON(http.StatusOK, gin.H{"message": "Post deleted successfully"})
 }
@@ -6,39 +6,73 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/VisarutJDev/social-media-api/config"
-	"github.com/VisarutJDev/social-media-api/database"
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/middlewares"
 	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+	"github.com/VisarutJDev/social-media-api/timeline"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func init() {
-	config.LoadConfig("../config/config_unittest.json")
-	database.Connect(config.Config.MongoURI)
+// newTestRouter returns a router that renders handler errors like the real one
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middlewares.ErrorHandler())
+	return router
+}
+
+// insertTestUser stores a user that requests in the test can act as
+func insertTestUser(repos *repositories.Repositories, username string, role string) models.User {
+	user := models.User{
+		ID:       primitive.NewObjectID(),
+		Username: username,
+		Password: "password123",
+		Role:     role,
+	}
+	repos.Users.Create(context.TODO(), user)
+	return user
+}
+
+// testRetention is how long the test controllers keep deleted posts restorable
+const testRetention = 24 * time.Hour
+
+// newTestPostController builds a PostController on repos with fan-out-on-read timelines
+func newTestPostController(repos *repositories.Repositories) *PostController {
+	return NewPostController(repos.Posts, repos.Comments, repos.Reactions, repos.Revisions, repos.Users, timeline.NewFanOutOnRead(repos.Posts, repos.Follows), testRetention)
+}
+
+// withUser stands in for middlewares.AuthMiddleware by setting the username it would put into the context
+func withUser(username string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("username", username)
+		c.Next()
+	}
 }
 
 func TestCreatePost(t *testing.T) {
-	// Set up the database connection
-	postCollection := database.Client.Database(config.Config.Database).Collection("posts")
-	postCollection.Drop(context.TODO()) // Clean up the collection before testing
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	author := insertTestUser(repos, "janedoe", models.RoleUser)
 
 	// Set up the Gin router
-	gin.SetMode(gin.TestMode)
-	router := gin.Default()
-	router.POST("/posts", CreatePost)
+	router := newTestRouter()
+	router.POST("/posts", withUser(author.Username), postController.CreatePost)
 
 	// Prepare the request payload
 	post := models.Post{
 		Title:   "Embracing Innovation in Product Management",
 		Content: "Exploring the latest trends in product management and how innovation can drive success. Dive into new methodologies, tools, and strategies that are shaping the future of our field.",
-		Author:  "Jane Doe, Product Management Expert",
+		Author:  "someone-else",
 	}
 	jsonValue, _ := json.Marshal(post)
 	req, _ := http.NewRequest("POST", "/posts", bytes.NewBuffer(jsonValue))
@@ -57,27 +91,59 @@ func TestCreatePost(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, post.Title, responsePost.Title)
 	assert.Equal(t, post.Content, responsePost.Content)
-	assert.Equal(t, post.Author, responsePost.Author)
+	// The author comes from the authenticated user, not the request body
+	assert.Equal(t, author.Username, responsePost.Author)
+	assert.Equal(t, author.ID, responsePost.AuthorID)
+	// Timestamps are set by the server
+	assert.False(t, responsePost.CreatedAt.IsZero())
+	assert.Equal(t, responsePost.CreatedAt, responsePost.UpdatedAt)
+	assert.Nil(t, responsePost.EditedAt)
+}
+
+func TestCreatePostValidation(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	author := insertTestUser(repos, "janedoe", models.RoleUser)
+
+	router := newTestRouter()
+	router.POST("/posts", withUser(author.Username), postController.CreatePost)
+
+	jsonValue, _ := json.Marshal(models.Post{Title: "   ", Content: "Some content"})
+	req, _ := http.NewRequest("POST", "/posts", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	var response models.Response
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FieldError{
+		{Field: "title", Code: models.CodeRequired, Message: "is required"},
+	}, response.Fields)
+
+	posts, _ := repos.Posts.List(context.TODO(), repositories.Page{Limit: 10})
+	assert.Empty(t, posts)
 }
 
 func TestGetPosts(t *testing.T) {
-	// Set up the database connection
-	postCollection := database.Client.Database(config.Config.Database).Collection("posts")
-	postCollection.Drop(context.TODO()) // Clean up the collection before testing
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
 
 	// Insert a test post
 	testPost := models.Post{
-		ID:      primitive.NewObjectID(),
-		Title:   "Mastering the Art of Product Development",
-		Content: "Unlock the secrets to successful product development with actionable insights and real-world examples. Transform your ideas into market-ready products with confidence.",
-		Author:  "John Smith, Senior Product Manager",
+		ID:       primitive.NewObjectID(),
+		Title:    "Mastering the Art of Product Development",
+		Content:  "Unlock the secrets to successful product development with actionable insights and real-world examples. Transform your ideas into market-ready products with confidence.",
+		AuthorID: primitive.NewObjectID(),
+		Author:   "johnsmith",
 	}
-	postCollection.InsertOne(context.TODO(), testPost)
+	repos.Posts.Create(context.TODO(), testPost)
 
 	// Set up the Gin router
-	gin.SetMode(gin.TestMode)
-	router := gin.Default()
-	router.GET("/posts", GetPosts)
+	router := newTestRouter()
+	router.GET("/posts", postController.GetPosts)
 
 	// Perform the request
 	req, _ := http.NewRequest("GET", "/posts", nil)
@@ -88,33 +154,179 @@ func TestGetPosts(t *testing.T) {
 	// Assert the response
 	assert.Equal(t, http.StatusOK, recorder.Code)
 
-	var responsePosts []models.Post
-	err := json.Unmarshal(recorder.Body.Bytes(), &responsePosts)
+	var responsePage models.PostPage
+	err := json.Unmarshal(recorder.Body.Bytes(), &responsePage)
 	assert.NoError(t, err)
+	responsePosts := responsePage.Data
 	assert.Len(t, responsePosts, 1)
 	assert.Equal(t, testPost.Title, responsePosts[0].Title)
 	assert.Equal(t, testPost.Content, responsePosts[0].Content)
 	assert.Equal(t, testPost.Author, responsePosts[0].Author)
+	assert.False(t, responsePage.Pagination.HasMore)
+	assert.Empty(t, responsePage.Pagination.NextCursor)
+}
+
+func TestGetPostsPagination(t *testing.T) {
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+
+	// Insert five posts; ObjectIDs created later sort newer
+	var ids []primitive.ObjectID
+	for i := 0; i < 5; i++ {
+		testPost := models.Post{
+			ID:      primitive.NewObjectID(),
+			Title:   "Post " + strconv.Itoa(i),
+			Content: "Content " + strconv.Itoa(i),
+			Author:  "johnsmith",
+		}
+		repos.Posts.Create(context.TODO(), testPost)
+		ids = append(ids, testPost.ID)
+	}
+
+	// Set up the Gin router
+	router := newTestRouter()
+	router.GET("/posts", postController.GetPosts)
+
+	getPage := func(query string) models.PostPage {
+		req, _ := http.NewRequest("GET", "/posts?"+query, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var page models.PostPage
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+		return page
+	}
+
+	// First page holds the two newest posts
+	page := getPage("limit=2")
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, ids[4], page.Data[0].ID)
+	assert.Equal(t, ids[3], page.Data[1].ID)
+	assert.True(t, page.Pagination.HasMore)
+	assert.Empty(t, page.Pagination.PrevCursor)
+
+	// Second page continues after the cursor
+	page = getPage("limit=2&cursor=" + page.Pagination.NextCursor)
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, ids[2], page.Data[0].ID)
+	assert.Equal(t, ids[1], page.Data[1].ID)
+	assert.True(t, page.Pagination.HasMore)
+	prevCursor := page.Pagination.PrevCursor
+
+	// Last page has a single post and nothing after it
+	page = getPage("limit=2&cursor=" + page.Pagination.NextCursor)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, ids[0], page.Data[0].ID)
+	assert.False(t, page.Pagination.HasMore)
+	assert.Empty(t, page.Pagination.NextCursor)
+
+	// Walking back from the second page returns the first one
+	page = getPage("limit=2&cursor=" + prevCursor)
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, ids[4], page.Data[0].ID)
+	assert.Equal(t, ids[3], page.Data[1].ID)
+	assert.False(t, page.Pagination.HasMore)
+	assert.NotEmpty(t, page.Pagination.NextCursor)
+
+	// The server caps the page size
+	page = getPage("limit=1000")
+	assert.Equal(t, MaxPageSize, page.Pagination.Limit)
+}
+
+func TestGetPostsSorted(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+
+	// Created in order, but the oldest was updated last and only the middle one edited
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	var ids []primitive.ObjectID
+	for i := 0; i < 3; i++ {
+		post := models.Post{
+			ID:        primitive.NewObjectID(),
+			Title:     "Post " + strconv.Itoa(i),
+			Content:   "Content " + strconv.Itoa(i),
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+			UpdatedAt: start.Add(time.Duration(3-i) * 24 * time.Hour),
+		}
+		if i == 1 {
+			post.EditedAt = &post.UpdatedAt
+		}
+		repos.Posts.Create(context.TODO(), post)
+		ids = append(ids, post.ID)
+	}
+
+	router := newTestRouter()
+	router.GET("/posts", postController.GetPosts)
+	getPage := func(query string) models.PostPage {
+		recorder := serve(router, "GET", "/posts?"+query)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var page models.PostPage
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+		return page
+	}
+	pageIDs := func(page models.PostPage) []primitive.ObjectID {
+		ids := []primitive.ObjectID{}
+		for _, post := range page.Data {
+			ids = append(ids, post.ID)
+		}
+		return ids
+	}
+
+	page := getPage("sort=updated_at&limit=2")
+	assert.Equal(t, []primitive.ObjectID{ids[0], ids[1]}, pageIDs(page))
+	page = getPage("sort=updated_at&limit=2&cursor=" + page.Pagination.NextCursor)
+	assert.Equal(t, []primitive.ObjectID{ids[2]}, pageIDs(page))
+	page = getPage("sort=updated_at&limit=2&cursor=" + page.Pagination.PrevCursor)
+	assert.Equal(t, []primitive.ObjectID{ids[0], ids[1]}, pageIDs(page))
+
+	page = getPage("sort=created_at")
+	assert.Equal(t, []primitive.ObjectID{ids[2], ids[1], ids[0]}, pageIDs(page))
+
+	page = getPage("sort=edited_at")
+	assert.Equal(t, []primitive.ObjectID{ids[1]}, pageIDs(page))
+	assert.True(t, page.Data[0].Edited)
+
+	recorder := serve(router, "GET", "/posts?sort=title")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// A cursor only continues the sort it came from
+	cursor := getPage("sort=updated_at&limit=1").Pagination.NextCursor
+	recorder = serve(router, "GET", "/posts?sort=created_at&cursor="+cursor)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestGetPostsInvalidCursor(t *testing.T) {
+	postController := newTestPostController(repositories.NewMemoryRepositories())
+
+	router := newTestRouter()
+	router.GET("/posts", postController.GetPosts)
+
+	req, _ := http.NewRequest("GET", "/posts?cursor=not-a-cursor", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
 }
 
 func TestGetPost(t *testing.T) {
-	// Set up the database connection
-	postCollection := database.Client.Database(config.Config.Database).Collection("posts")
-	postCollection.Drop(context.TODO()) // Clean up the collection before testing
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
 
 	// Insert a test post
 	testPost := models.Post{
-		ID:      primitive.NewObjectID(),
-		Title:   "The Power of User-Centric Design",
-		Content: "Discover how user-centric design can revolutionize your product strategy. Learn to prioritize user needs and create products that resonate and drive engagement.",
-		Author:  "Emily Johnson, UX Designer and Product Specialist",
+		ID:       primitive.NewObjectID(),
+		Title:    "The Power of User-Centric Design",
+		Content:  "Discover how user-centric design can revolutionize your product strategy. Learn to prioritize user needs and create products that resonate and drive engagement.",
+		AuthorID: primitive.NewObjectID(),
+		Author:   "emilyjohnson",
 	}
-	postCollection.InsertOne(context.TODO(), testPost)
+	repos.Posts.Create(context.TODO(), testPost)
 
 	// Set up the Gin router
-	gin.SetMode(gin.TestMode)
-	router := gin.Default()
-	router.GET("/posts/:id", GetPost)
+	router := newTestRouter()
+	router.GET("/posts/:id", postController.GetPost)
 
 	// Perform the request
 	req, _ := http.NewRequest("GET", "/posts/"+testPost.ID.Hex(), nil)
@@ -133,35 +345,63 @@ func TestGetPost(t *testing.T) {
 	assert.Equal(t, testPost.Author, responsePost.Author)
 }
 
+func TestGetPostNotFound(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	router := newTestRouter()
+	router.GET("/posts/:id", postController.GetPost)
+
+	tests := []struct {
+		id     string
+		status int
+		code   string
+	}{
+		{id: primitive.NewObjectID().Hex(), status: http.StatusNotFound, code: apperrors.CodeNotFound},
+		{id: "not-an-id", status: http.StatusBadRequest, code: apperrors.CodeInvalidID},
+	}
+	for _, tt := range tests {
+		recorder := serve(router, "GET", "/posts/"+tt.id)
+		assert.Equal(t, tt.status, recorder.Code)
+		var response models.Response
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.code, response.Code)
+	}
+}
+
 func TestUpdatePost(t *testing.T) {
-	// Set up the database connection
-	postCollection := database.Client.Database(config.Config.Database).Collection("posts")
-	postCollection.Drop(context.TODO()) // Clean up the collection before testing
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	author := insertTestUser(repos, "michaelbrown", models.RoleUser)
 
 	// Insert a test post
 	testPost := models.Post{
-		ID:      primitive.NewObjectID(),
-		Title:   "Leveraging Data for Product Success",
-		Content: "Harness the power of data to enhance your product development process. From analytics to user feedback, find out how data can inform and guide your decisions.",
-		Author:  "Michael Brown, Data-Driven Product Manager",
+		ID:       primitive.NewObjectID(),
+		Title:    "Leveraging Data for Product Success",
+		Content:  "Harness the power of data to enhance your product development process. From analytics to user feedback, find out how data can inform and guide your decisions.",
+		AuthorID: author.ID,
+		Author:   author.Username,
+		Version:  1,
 	}
-	postCollection.InsertOne(context.TODO(), testPost)
+	repos.Posts.Create(context.TODO(), testPost)
 
 	// Set up the Gin router
-	gin.SetMode(gin.TestMode)
-	router := gin.Default()
-	router.PUT("/posts/:id", UpdatePost)
+	router := newTestRouter()
+	router.PUT("/posts/:id", withUser(author.Username), postController.UpdatePost)
 
 	// Prepare the request payload
 	updatedPost := models.Post{
-		Title:   "Future-Proofing Your Product Strategy",
-		Content: "Stay ahead of the curve with future-proof strategies in product management. Learn to anticipate market trends and adapt your approach for sustained growth and success.",
-		Author:  "Sarah Lee, Product Strategy Consultant",
+		Title:     "Future-Proofing Your Product Strategy",
+		Content:   "Stay ahead of the curve with future-proof strategies in product management. Learn to anticipate market trends and adapt your approach for sustained growth and success.",
+		Author:    "sarahlee",
+		CreatedAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
 	}
 	jsonValue, _ := json.Marshal(updatedPost)
 	req, _ := http.NewRequest("PUT", "/posts/"+testPost.ID.Hex(), bytes.NewBuffer(jsonValue))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer your-secret-token")
+	req.Header.Set("If-Match", `"1"`)
 
 	// Perform the request
 	recorder := httptest.NewRecorder()
@@ -169,46 +409,399 @@ func TestUpdatePost(t *testing.T) {
 
 	// Assert the response
 	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, strings.HasPrefix(recorder.Header().Get("ETag"), `"2-`))
 
-	// Verify the update in the database
-	var responsePost models.Post
-	err := postCollection.FindOne(context.TODO(), bson.M{"_id": testPost.ID}).Decode(&responsePost)
+	// Verify the update in the repository
+	responsePost, err := repos.Posts.FindByID(context.TODO(), testPost.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, updatedPost.Title, responsePost.Title)
 	assert.Equal(t, updatedPost.Content, responsePost.Content)
+	assert.Equal(t, author.Username, responsePost.Author)
+	assert.Equal(t, author.ID, responsePost.AuthorID)
+	// The server keeps the creation time and marks the post edited
+	assert.Equal(t, testPost.CreatedAt, responsePost.CreatedAt)
+	assert.NotNil(t, responsePost.EditedAt)
+	assert.Equal(t, responsePost.UpdatedAt, *responsePost.EditedAt)
+	assert.Equal(t, int64(2), responsePost.Version)
 }
 
-func TestDeletePost(t *testing.T) {
-	// Set up the database connection
-	postCollection := database.Client.Database(config.Config.Database).Collection("posts")
-	postCollection.Drop(context.TODO()) // Clean up the collection before testing
+func TestUpdatePostUnchanged(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	post := insertTestPost(repos, insertTestUser(repos, "michaelbrown", models.RoleUser))
+
+	router := newTestRouter()
+	router.PUT("/posts/:id", withUser("michaelbrown"), postController.UpdatePost)
+	router.GET("/posts/:id", postController.GetPost)
+
+	jsonValue, _ := json.Marshal(models.Post{Title: post.Title, Content: post.Content, Version: post.Version})
+	req, _ := http.NewRequest("PUT", "/posts/"+post.ID.Hex(), bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Saving the same title and content is an update but not an edit
+	stored, err := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, stored.EditedAt)
+	assert.True(t, stored.UpdatedAt.After(post.UpdatedAt))
+
+	recorder = serve(router, "GET", "/posts/"+post.ID.Hex())
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"edited":false`)
+}
+
+func TestUpdatePostByOtherUser(t *testing.T) {
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	author := insertTestUser(repos, "michaelbrown", models.RoleUser)
+	intruder := insertTestUser(repos, "sarahlee", models.RoleUser)
 
 	// Insert a test post
+	testPost := models.Post{
+		ID:       primitive.NewObjectID(),
+		Title:    "Leveraging Data for Product Success",
+		Content:  "Harness the power of data to enhance your product development process.",
+		AuthorID: author.ID,
+		Author:   author.Username,
+	}
+	repos.Posts.Create(context.TODO(), testPost)
+
+	// Set up the Gin router
+	router := newTestRouter()
+	router.PUT("/posts/:id", withUser(intruder.Username), postController.UpdatePost)
+
+	// Prepare the request payload
+	updatedPost := models.Post{
+		Title:   "Hijacked",
+		Content: "This post now belongs to someone else.",
+	}
+	jsonValue, _ := json.Marshal(updatedPost)
+	req, _ := http.NewRequest("PUT", "/posts/"+testPost.ID.Hex(), bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Perform the request
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// Assert the response
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Verify the post is untouched
+	responsePost, err := repos.Posts.FindByID(context.TODO(), testPost.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, testPost.Title, responsePost.Title)
+	assert.Equal(t, testPost.Content, responsePost.Content)
+}
+
+func TestUpdatePostByAdmin(t *testing.T) {
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	author := insertTestUser(repos, "michaelbrown", models.RoleUser)
+	admin := insertTestUser(repos, "admin", models.RoleAdmin)
+
+	// Insert a test post
+	testPost := models.Post{
+		ID:       primitive.NewObjectID(),
+		Title:    "Leveraging Data for Product Success",
+		Content:  "Harness the power of data to enhance your product development process.",
+		AuthorID: author.ID,
+		Author:   author.Username,
+	}
+	repos.Posts.Create(context.TODO(), testPost)
+
+	// Set up the Gin router
+	router := newTestRouter()
+	router.PUT("/posts/:id", withUser(admin.Username), postController.UpdatePost)
+
+	// Prepare the request payload
+	updatedPost := models.Post{
+		Title:   "Moderated title",
+		Content: "Moderated content.",
+	}
+	jsonValue, _ := json.Marshal(updatedPost)
+	req, _ := http.NewRequest("PUT", "/posts/"+testPost.ID.Hex(), bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
+
+	// Perform the request
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// Assert the response
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Verify the update kept the original author
+	responsePost, err := repos.Posts.FindByID(context.TODO(), testPost.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, updatedPost.Title, responsePost.Title)
+	assert.Equal(t, author.Username, responsePost.Author)
+	assert.Equal(t, author.ID, responsePost.AuthorID)
+}
+
+func TestUpdatePostWithoutAuthorID(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	insertTestUser(repos, "michaelbrown", models.RoleUser)
+	insertTestUser(repos, "sarahlee", models.RoleUser)
+
+	// A post written before author_id was stored
 	testPost := models.Post{
 		ID:      primitive.NewObjectID(),
-		Title:   "Agile Transformation in Product Management",
-		Content: "Transform your product management approach with Agile methodologies. Learn how to foster collaboration, increase efficiency, and deliver high-quality products faster.",
-		Author:  "David Green, Agile Coach and Product Leader",
+		Title:   "Leveraging Data for Product Success",
+		Content: "Harness the power of data to enhance your product development process.",
+		Author:  "michaelbrown",
+		Version: 1,
+	}
+	repos.Posts.Create(context.TODO(), testPost)
+	path := "/posts/" + testPost.ID.Hex()
+	updatedPost := models.Post{Title: "Renamed", Content: testPost.Content}
+
+	router := newTestRouter()
+	router.PUT("/posts/:id", withUser("sarahlee"), postController.UpdatePost)
+	assert.Equal(t, http.StatusForbidden, putIfMatch(router, path, `"1"`, updatedPost).Code)
+
+	// Its author is still the one named on it
+	router = newTestRouter()
+	router.PUT("/posts/:id", withUser("michaelbrown"), postController.UpdatePost)
+	assert.Equal(t, http.StatusOK, putIfMatch(router, path, `"1"`, updatedPost).Code)
+}
+
+func TestUpdatePostMissingFields(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	post := insertTestPost(repos, insertTestUser(repos, "michaelbrown", models.RoleUser))
+
+	router := newTestRouter()
+	router.PUT("/posts/:id", withUser("michaelbrown"), postController.UpdatePost)
+
+	// PUT replaces the whole post, so leaving out the content is not a partial update
+	recorder := sendPost(router, "PUT", "/posts/"+post.ID.Hex(), models.Post{Title: "Only a title"})
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	var response models.Response
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Equal(t, []models.FieldError{{Field: "content", Code: models.CodeRequired, Message: "is required"}}, response.Fields)
+
+	stored, _ := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.Equal(t, post.Content, stored.Content)
+}
+
+// sendPatch sends body to path as a patch document of contentType, sending
+// ifMatch as If-Match unless it is empty
+func sendPatch(router *gin.Engine, path string, contentType string, ifMatch string, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("PATCH", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func newPatchRouter(repos *repositories.Repositories, username string) *gin.Engine {
+	postController := newTestPostController(repos)
+	router := newTestRouter()
+	router.Use(withUser(username))
+	router.PATCH("/posts/:id", postController.PatchPost)
+	router.GET("/posts/:id/revisions/:number", postController.GetRevision)
+	return router
+}
+
+func TestPatchPost(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "michaelbrown", models.RoleUser))
+	router := newPatchRouter(repos, "michaelbrown")
+	path := "/posts/" + post.ID.Hex()
+
+	// A merge patch changes only the fields it names
+	recorder := sendPatch(router, path, "application/merge-patch+json", "", `{"title":"Patched title","version":1}`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var patched models.Post
+	json.Unmarshal(recorder.Body.Bytes(), &patched)
+	assert.Equal(t, "Patched title", patched.Title)
+	assert.Equal(t, post.Content, patched.Content)
+	assert.True(t, patched.Edited)
+	assert.Equal(t, int64(2), patched.Version)
+
+	stored, _ := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.Equal(t, "Patched title", stored.Title)
+	assert.Equal(t, post.Content, stored.Content)
+	revision := getRevision(t, router, post, 2)
+	assert.Equal(t, []models.FieldChange{{Field: models.FieldTitle, From: post.Title, To: "Patched title"}}, revision.Changes)
+
+	// A JSON Patch can guard its change with a test
+	recorder = sendPatch(router, path, "application/json-patch+json", "",
+		`[{"op":"test","path":"/version","value":2},{"op":"test","path":"/title","value":"Patched title"},{"op":"replace","path":"/content","value":"Patched content"}]`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	json.Unmarshal(recorder.Body.Bytes(), &patched)
+	assert.Equal(t, "Patched title", patched.Title)
+	assert.Equal(t, "Patched content", patched.Content)
+
+	recorder = sendPatch(router, path, "application/json-patch+json", "",
+		`[{"op":"test","path":"/version","value":3},{"op":"test","path":"/title","value":"Stale title"},{"op":"replace","path":"/content","value":"Lost update"}]`)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	stored, _ = repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.Equal(t, "Patched content", stored.Content)
+}
+
+func TestPatchPostInvalid(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "michaelbrown", models.RoleUser))
+	insertTestUser(repos, "sarahlee", models.RoleUser)
+	router := newPatchRouter(repos, "michaelbrown")
+	path := "/posts/" + post.ID.Hex()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		fields      []models.FieldError
+	}{
+		{"plain json", "application/json", `{"title":"New"}`, http.StatusUnsupportedMediaType, nil},
+		{"malformed", "application/merge-patch+json", `{"title":`, http.StatusBadRequest, nil},
+		{"unknown operation", "application/json-patch+json", `[{"op":"rename","path":"/title"}]`, http.StatusBadRequest, nil},
+		{"missing path", "application/json-patch+json", `[{"op":"remove","path":"/summary"}]`, http.StatusConflict, nil},
+		{"blank title", "application/merge-patch+json", `{"title":" "}`, http.StatusUnprocessableEntity,
+			[]models.FieldError{{Field: "title", Code: models.CodeRequired, Message: "is required"}}},
+		{"removed content", "application/json-patch+json", `[{"op":"remove","path":"/content"}]`, http.StatusUnprocessableEntity,
+			[]models.FieldError{{Field: "content", Code: models.CodeRequired, Message: "is required"}}},
+		{"not a string", "application/merge-patch+json", `{"title":42}`, http.StatusUnprocessableEntity,
+			[]models.FieldError{{Field: "title", Code: models.CodeInvalid, Message: "must be a string"}}},
+		{"read only fields", "application/merge-patch+json", `{"author":"sarahlee","comment_count":null,"pinned":true}`, http.StatusUnprocessableEntity,
+			[]models.FieldError{
+				{Field: "author", Code: models.CodeReadOnly, Message: "cannot be changed"},
+				{Field: "comment_count", Code: models.CodeReadOnly, Message: "cannot be changed"},
+				{Field: "pinned", Code: models.CodeReadOnly, Message: "cannot be changed"},
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sendPatch(router, path, tt.contentType, `"1"`, tt.body)
+			assert.Equal(t, tt.status, recorder.Code)
+			var response models.Response
+			json.Unmarshal(recorder.Body.Bytes(), &response)
+			assert.Equal(t, tt.fields, response.Fields)
+		})
+	}
+
+	recorder := sendPatch(router, path, "text/plain", "", "title")
+	assert.Equal(t, "application/merge-patch+json, application/json-patch+json", recorder.Header().Get("Accept-Patch"))
+	recorder = sendPatch(newPatchRouter(repos, "sarahlee"), path, "application/merge-patch+json", `"1"`, `{"title":"Hijacked"}`)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	stored, _ := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.Equal(t, post.Title, stored.Title)
+	assert.Equal(t, post.Content, stored.Content)
+}
+
+func TestDeletePost(t *testing.T) {
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	author := insertTestUser(repos, "davidgreen", models.RoleUser)
+
+	// Insert a test post
+	testPost := models.Post{
+		ID:       primitive.NewObjectID(),
+		Title:    "Agile Transformation in Product Management",
+		Content:  "Transform your product management approach with Agile methodologies. Learn how to foster collaboration, increase efficiency, and deliver high-quality products faster.",
+		AuthorID: author.ID,
+		Author:   author.Username,
+		Version:  1,
 	}
-	postCollection.InsertOne(context.TODO(), testPost)
+	repos.Posts.Create(context.TODO(), testPost)
 
 	// Set up the Gin router
-	gin.SetMode(gin.TestMode)
-	router := gin.Default()
-	router.DELETE("/posts/:id", DeletePost)
+	router := newTestRouter()
+	router.DELETE("/posts/:id", withUser(author.Username), postController.DeletePost)
 
 	// Perform the request
 	req, _ := http.NewRequest("DELETE", "/posts/"+testPost.ID.Hex(), nil)
 	req.Header.Set("Authorization", "Bearer your-secret-token")
+	req.Header.Set("If-Match", `"1"`)
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
 	// Assert the response
 	assert.Equal(t, http.StatusOK, recorder.Code)
 
-	// Verify the deletion in the database
-	var responsePost models.Post
-	err := postCollection.FindOne(context.TODO(), bson.M{"_id": testPost.ID}).Decode(&responsePost)
+	// Verify the deletion in the repository
+	_, err := repos.Posts.FindByID(context.TODO(), testPost.ID)
 	assert.Error(t, err)
-	assert.Equal(t, mongo.ErrNoDocuments, err)
+	assert.Equal(t, repositories.ErrNotFound, err)
+}
+
+func TestDeletePostByOtherUser(t *testing.T) {
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	author := insertTestUser(repos, "davidgreen", models.RoleUser)
+	intruder := insertTestUser(repos, "sarahlee", models.RoleUser)
+
+	// Insert a test post
+	testPost := models.Post{
+		ID:       primitive.NewObjectID(),
+		Title:    "Agile Transformation in Product Management",
+		Content:  "Transform your product management approach with Agile methodologies.",
+		AuthorID: author.ID,
+		Author:   author.Username,
+	}
+	repos.Posts.Create(context.TODO(), testPost)
+
+	// Set up the Gin router
+	router := newTestRouter()
+	router.DELETE("/posts/:id", withUser(intruder.Username), postController.DeletePost)
+
+	// Perform the request
+	req, _ := http.NewRequest("DELETE", "/posts/"+testPost.ID.Hex(), nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// Assert the response
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Verify the post still exists
+	_, err := repos.Posts.FindByID(context.TODO(), testPost.ID)
+	assert.NoError(t, err)
+}
+
+func TestDeletePostByAdmin(t *testing.T) {
+	// Set up the in-memory repositories
+	repos := repositories.NewMemoryRepositories()
+	postController := newTestPostController(repos)
+	author := insertTestUser(repos, "davidgreen", models.RoleUser)
+	admin := insertTestUser(repos, "admin", models.RoleAdmin)
+
+	// Insert a test post
+	testPost := models.Post{
+		ID:       primitive.NewObjectID(),
+		Title:    "Agile Transformation in Product Management",
+		Content:  "Transform your product management approach with Agile methodologies.",
+		AuthorID: author.ID,
+		Author:   author.Username,
+		Version:  1,
+	}
+	repos.Posts.Create(context.TODO(), testPost)
+
+	// Set up the Gin router
+	router := newTestRouter()
+	router.DELETE("/posts/:id", withUser(admin.Username), postController.DeletePost)
+
+	// Perform the request, naming the version in the body instead of If-Match
+	req, _ := http.NewRequest("DELETE", "/posts/"+testPost.ID.Hex(), bytes.NewBufferString(`{"version":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// Assert the response
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Verify the deletion in the repository
+	_, err := repos.Posts.FindByID(context.TODO(), testPost.ID)
+	assert.Equal(t, repositories.ErrNotFound, err)
 }
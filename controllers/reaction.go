@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReactionController serves reactions to posts
+type ReactionController struct {
+	Reactions repositories.ReactionRepository
+	Posts     repositories.PostRepository
+	Users     repositories.UserRepository
+	Types     []string // Allowed reaction types
+}
+
+// NewReactionController allows models.DefaultReactionTypes when types is empty
+func NewReactionController(reactions repositories.ReactionRepository, posts repositories.PostRepository, users repositories.UserRepository, types []string) *ReactionController {
+	if len(types) == 0 {
+		types = models.DefaultReactionTypes
+	}
+	return &ReactionController{Reactions: reactions, Posts: posts, Users: users, Types: types}
+}
+
+// setViewerReactions fills in the viewer fields of posts for userID
+func setViewerReactions(ctx context.Context, reactions repositories.ReactionRepository, userID primitive.ObjectID, posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ids := make([]primitive.ObjectID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	types, err := reactions.ForUser(ctx, userID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range posts {
+		posts[i].ViewerReaction = types[posts[i].ID]
+		posts[i].ViewerReacted = posts[i].ViewerReaction != ""
+	}
+	return nil
+}
+
+// respondWithPost writes the current state of the post with id as seen by user
+func (rc *ReactionController) respondWithPost(c *gin.Context, id primitive.ObjectID, user models.User) {
+	post, err := rc.Posts.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(lookupError(err, "Post not found"))
+		return
+	}
+	posts := []models.Post{post}
+	if err := setViewerReactions(c.Request.Context(), rc.Reactions, user.ID, posts); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, posts[0])
+}
+
+// React godoc
+//
+//	@Summary		React to Post
+//	@Description	Set the caller's reaction to a post. Reacting again with the same type changes nothing; another type replaces it.
+//	@ID				React
+//	@Tags			reaction
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string					true	"id of the post"
+//	@Param			reaction	body		models.ReactionInput	true	"Reaction type"
+//	@Success		200			{object}	models.Post				"OK"
+//	@Failure		400			{object}	models.Response			"Bad Request"
+//	@Failure		401			{object}	models.Response			"Unauthorized"
+//	@Failure		404			{object}	models.Response			"Not Found"
+//	@Failure		422			{object}	models.Response			"Unprocessable Entity"
+//	@Failure		500			{object}	models.Response			"Internal Server Error"
+//	@Router			/posts/{id}/reactions [post]
+func (rc *ReactionController) React(c *gin.Context) {
+	user, err := currentUser(c, rc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	post, err := pathPost(c, rc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var input models.ReactionInput
+	if err := bindJSON(c, &input); err != nil {
+		c.Error(err)
+		return
+	}
+	if !slices.Contains(rc.Types, input.Type) {
+		c.Error(apperrors.Validation(models.FieldError{
+			Field:   "type",
+			Code:    models.CodeInvalidChoice,
+			Message: "must be one of " + strings.Join(rc.Types, ", "),
+		}))
+		return
+	}
+
+	ctx := c.Request.Context()
+	previous, err := rc.Reactions.Set(ctx, models.Reaction{
+		ID:        primitive.NewObjectID(),
+		PostID:    post.ID,
+		UserID:    user.ID,
+		Type:      input.Type,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	// Counters move only by the change Set observed, so concurrent requests add up
+	if previous != input.Type {
+		deltas := map[string]int64{input.Type: 1}
+		if previous != "" {
+			deltas[previous] = -1
+		}
+		if err := rc.Posts.IncrementReactionCounts(ctx, post.ID, deltas); err != nil {
+			c.Error(lookupError(err, "Post not found"))
+			return
+		}
+	}
+	rc.respondWithPost(c, post.ID, user)
+}
+
+// Unreact godoc
+//
+//	@Summary		Remove Reaction
+//	@Description	Remove the caller's reaction to a post, if any
+//	@ID				Unreact
+//	@Tags			reaction
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string			true	"id of the post"
+//	@Success		200	{object}	models.Post		"OK"
+//	@Failure		400	{object}	models.Response	"Bad Request"
+//	@Failure		401	{object}	models.Response	"Unauthorized"
+//	@Failure		404	{object}	models.Response	"Not Found"
+//	@Failure		500	{object}	models.Response	"Internal Server Error"
+//	@Router			/posts/{id}/reactions [delete]
+func (rc *ReactionController) Unreact(c *gin.Context) {
+	user, err := currentUser(c, rc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	post, err := pathPost(c, rc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	previous, err := rc.Reactions.Remove(ctx, post.ID, user.ID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	if err == nil {
+		if err := rc.Posts.IncrementReactionCounts(ctx, post.ID, map[string]int64{previous: -1}); err != nil {
+			c.Error(lookupError(err, "Post not found"))
+			return
+		}
+	}
+	rc.respondWithPost(c, post.ID, user)
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newReactionRouter serves the reaction routes and GetPost acting as username
+func newReactionRouter(repos *repositories.Repositories, username string) *gin.Engine {
+	reactionController := NewReactionController(repos.Reactions, repos.Posts, repos.Users, nil)
+	postController := newTestPostController(repos)
+
+	router := newTestRouter()
+	router.Use(withUser(username))
+	router.GET("/posts/:id", postController.GetPost)
+	router.POST("/posts/:id/reactions", reactionController.React)
+	router.DELETE("/posts/:id/reactions", reactionController.Unreact)
+	return router
+}
+
+func postReaction(router *gin.Engine, postID primitive.ObjectID, reactionType string) (*httptest.ResponseRecorder, models.Post) {
+	body, _ := json.Marshal(models.ReactionInput{Type: reactionType})
+	req, _ := http.NewRequest("POST", "/posts/"+postID.Hex()+"/reactions", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	var post models.Post
+	json.Unmarshal(recorder.Body.Bytes(), &post)
+	return recorder, post
+}
+
+func TestReact(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	insertTestUser(repos, "bob", models.RoleUser)
+	post := insertTestPost(repos, alice)
+	router := newReactionRouter(repos, "bob")
+
+	recorder, reacted := postReaction(router, post.ID, "like")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, int64(1), reacted.Reactions["like"])
+	assert.True(t, reacted.ViewerReacted)
+	assert.Equal(t, "like", reacted.ViewerReaction)
+
+	// Reacting twice with the same type is a no-op
+	_, reacted = postReaction(router, post.ID, "like")
+	assert.Equal(t, int64(1), reacted.Reactions["like"])
+
+	// Another type replaces the reaction
+	_, reacted = postReaction(router, post.ID, "love")
+	assert.Equal(t, int64(0), reacted.Reactions["like"])
+	assert.Equal(t, int64(1), reacted.Reactions["love"])
+	assert.Equal(t, "love", reacted.ViewerReaction)
+
+	// Other users see the counts but not bob's flag
+	recorder = serve(newReactionRouter(repos, "alice"), "GET", "/posts/"+post.ID.Hex())
+	var viewed models.Post
+	json.Unmarshal(recorder.Body.Bytes(), &viewed)
+	assert.Equal(t, int64(1), viewed.Reactions["love"])
+	assert.False(t, viewed.ViewerReacted)
+}
+
+func TestReactUnknownType(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	post := insertTestPost(repos, alice)
+
+	recorder, _ := postReaction(newReactionRouter(repos, "alice"), post.ID, "meh")
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder, _ = postReaction(newReactionRouter(repos, "alice"), primitive.NewObjectID(), "like")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestUnreact(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	post := insertTestPost(repos, alice)
+	router := newReactionRouter(repos, "alice")
+	postReaction(router, post.ID, "laugh")
+
+	recorder := serve(router, "DELETE", "/posts/"+post.ID.Hex()+"/reactions")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var unreacted models.Post
+	json.Unmarshal(recorder.Body.Bytes(), &unreacted)
+	assert.Equal(t, int64(0), unreacted.Reactions["laugh"])
+	assert.False(t, unreacted.ViewerReacted)
+
+	// Removing a reaction that is already gone changes nothing
+	recorder = serve(router, "DELETE", "/posts/"+post.ID.Hex()+"/reactions")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	stored, _ := repos.Posts.FindByID(context.Background(), post.ID)
+	assert.Equal(t, int64(0), stored.Reactions["laugh"])
+}
+
+func TestReactConcurrently(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	post := insertTestPost(repos, alice)
+
+	const users = 20
+	var wg sync.WaitGroup
+	for i := 0; i < users; i++ {
+		username := fmt.Sprintf("user%d", i)
+		insertTestUser(repos, username, models.RoleUser)
+		router := newReactionRouter(repos, username)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every user flips between types a few times and settles on like
+			for _, reactionType := range []string{"like", "wow", "like", "like"} {
+				postReaction(router, post.ID, reactionType)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stored, _ := repos.Posts.FindByID(context.Background(), post.ID)
+	assert.Equal(t, int64(users), stored.Reactions["like"])
+	assert.Equal(t, int64(0), stored.Reactions["wow"])
+}
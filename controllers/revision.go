@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newRevision records post as number, edited by editor from previous
+func newRevision(previous models.Post, post models.Post, number int, editor models.User) models.Revision {
+	changes := []models.FieldChange{}
+	if previous.Title != post.Title {
+		changes = append(changes, models.FieldChange{Field: models.FieldTitle, From: previous.Title, To: post.Title})
+	}
+	if previous.Content != post.Content {
+		changes = append(changes, models.FieldChange{Field: models.FieldContent, From: previous.Content, To: post.Content})
+	}
+	return models.Revision{
+		ID:        primitive.NewObjectID(),
+		PostID:    post.ID,
+		Number:    number,
+		EditorID:  editor.ID,
+		Editor:    editor.Username,
+		CreatedAt: post.UpdatedAt,
+		Title:     post.Title,
+		Content:   post.Content,
+		Changes:   changes,
+	}
+}
+
+// recordRevision notes the edit of a post from before to after by editor. Posts
+// written before revisions were kept first get their original as revision 1.
+// The edit is stored either way, so failures are only logged.
+func (pc *PostController) recordRevision(c *gin.Context, editor models.User, before models.Post, after models.Post, restoredFrom int) {
+	ctx := c.Request.Context()
+	latest, err := pc.Revisions.Latest(ctx, before.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		author := models.User{ID: before.AuthorID, Username: before.Author}
+		original := before
+		original.UpdatedAt = before.CreatedAt
+		latest = newRevision(models.Post{}, original, 1, author)
+		err = pc.Revisions.Create(ctx, latest)
+	}
+	if err == nil {
+		after.ID = before.ID
+		revision := newRevision(before, after, latest.Number+1, editor)
+		revision.RestoredFrom = restoredFrom
+		err = pc.Revisions.Create(ctx, revision)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record revision", "post_id", before.ID.Hex(), "error", err)
+	}
+}
+
+// pathRevision loads the revision of post named by the :number path parameter
+func (pc *PostController) pathRevision(c *gin.Context, post models.Post) (models.Revision, error) {
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil || number < 1 {
+		return models.Revision{}, apperrors.BadRequest("Invalid revision number")
+	}
+	revision, err := pc.Revisions.Find(c.Request.Context(), post.ID, number)
+	if err != nil {
+		return revision, lookupError(err, "Revision not found")
+	}
+	return revision, nil
+}
+
+// GetRevisions godoc
+//
+//	@Summary		Get Revisions
+//	@Description	Get the edit history of a post newest first, paginated with an opaque cursor
+//	@ID				GetRevisions
+//	@Tags			post
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id		path		string				true	"id of post"
+//	@Param			limit	query		int					false	"page size, at most 100"	default(20)
+//	@Param			cursor	query		string				false	"next_cursor or prev_cursor from a previous page"
+//	@Success		200		{object}	models.RevisionPage	"OK"
+//	@Failure		400		{object}	models.Response		"Bad Request"
+//	@Failure		401		{object}	models.Response		"Unauthorized"
+//	@Failure		404		{object}	models.Response		"Not Found"
+//	@Failure		500		{object}	models.Response		"Internal Server Error"
+//	@Router			/posts/{id}/revisions [get]
+func (pc *PostController) GetRevisions(c *gin.Context) {
+	post, err := pathPost(c, pc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	page, err := parsePage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	revisions, err := pc.Revisions.List(c.Request.Context(), post.ID, fetchPage(page))
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	revisions, hasMore := trimPage(page, revisions)
+	var first, last primitive.ObjectID
+	if len(revisions) > 0 {
+		first, last = revisions[0].ID, revisions[len(revisions)-1].ID
+	}
+	c.JSON(http.StatusOK, models.RevisionPage{
+		Data:       revisions,
+		Pagination: newPagination(page, hasMore, first, last),
+	})
+}
+
+// GetRevision godoc
+//
+//	@Summary		Get Revision
+//	@Description	Get one revision of a post by its number
+//	@ID				GetRevision
+//	@Tags			post
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id		path		string			true	"id of post"
+//	@Param			number	path		int				true	"revision number"
+//	@Success		200		{object}	models.Revision	"OK"
+//	@Failure		400		{object}	models.Response	"Bad Request"
+//	@Failure		401		{object}	models.Response	"Unauthorized"
+//	@Failure		404		{object}	models.Response	"Not Found"
+//	@Failure		500		{object}	models.Response	"Internal Server Error"
+//	@Router			/posts/{id}/revisions/{number} [get]
+func (pc *PostController) GetRevision(c *gin.Context) {
+	post, err := pathPost(c, pc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	revision, err := pc.pathRevision(c, post)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// RestoreRevision godoc
+//
+//	@Summary		Restore Revision
+//	@Description	Put the title and content of a revision back on the post, which records a new revision. If-Match is optional, as the revision already names the content to restore; when given, the post must still be at that version.
+//	@ID				RestoreRevision
+//	@Tags			post
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id			path		string			true	"id of post"
+//	@Param			number		path		int				true	"revision number to restore"
+//	@Param			If-Match	header		string			false	"ETag of the post as last read"
+//	@Success		200			{object}	models.Post		"OK"
+//	@Failure		400			{object}	models.Response	"Bad Request"
+//	@Failure		401			{object}	models.Response	"Unauthorized"
+//	@Failure		403			{object}	models.Response	"Forbidden"
+//	@Failure		404			{object}	models.Response	"Not Found"
+//	@Failure		412			{object}	models.Response	"Precondition Failed"
+//	@Failure		500			{object}	models.Response	"Internal Server Error"
+//	@Router			/posts/{id}/revisions/{number}/restore [post]
+func (pc *PostController) RestoreRevision(c *gin.Context) {
+	user, err := currentUser(c, pc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	existing, err := pathPost(c, pc.Posts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !canModifyPost(user, existing) {
+		c.Error(apperrors.Forbidden("You are not allowed to update this post"))
+		return
+	}
+	// The revision names the content to restore, so a precondition is only checked when given
+	if header := c.GetHeader("If-Match"); header != "" && !ifMatch(header, existing.Version) {
+		c.Error(errPostChanged)
+		return
+	}
+	revision, err := pc.pathRevision(c, existing)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	post := existing
+	// Restoring what the post already says changes nothing
+	if revision.Title != existing.Title || revision.Content != existing.Content {
+		post.Title = revision.Title
+		post.Content = revision.Content
+		post.UpdatedAt = models.Now()
+		post.EditedAt = &post.UpdatedAt
+		post.Version = existing.Version + 1
+		if err := pc.Posts.Update(c.Request.Context(), post.ID, existing.Version, post); err != nil {
+			c.Error(postWriteError(err))
+			return
+		}
+		pc.recordRevision(c, user, existing, post, revision.Number)
+	}
+
+	pc.renderPost(c, post)
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRevisionRouter serves the post and revision routes acting as username
+func newRevisionRouter(repos *repositories.Repositories, username string) *gin.Engine {
+	postController := newTestPostController(repos)
+
+	router := newTestRouter()
+	router.Use(withUser(username))
+	router.POST("/posts", postController.CreatePost)
+	router.PUT("/posts/:id", postController.UpdatePost)
+	router.GET("/posts/:id/revisions", postController.GetRevisions)
+	router.GET("/posts/:id/revisions/:number", postController.GetRevision)
+	router.POST("/posts/:id/revisions/:number/restore", postController.RestoreRevision)
+	return router
+}
+
+func sendPost(router *gin.Engine, method string, path string, post models.Post) *httptest.ResponseRecorder {
+	jsonValue, _ := json.Marshal(post)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func getRevision(t *testing.T, router *gin.Engine, post models.Post, number int) models.Revision {
+	recorder := serve(router, "GET", "/posts/"+post.ID.Hex()+"/revisions/"+strconv.Itoa(number))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var revision models.Revision
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &revision))
+	return revision
+}
+
+func TestRevisions(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	insertTestUser(repos, "alice", models.RoleUser)
+	router := newRevisionRouter(repos, "alice")
+
+	recorder := sendPost(router, "POST", "/posts", models.Post{Title: "Hello", Content: "First draft"})
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	var post models.Post
+	json.Unmarshal(recorder.Body.Bytes(), &post)
+
+	recorder = sendPost(router, "PUT", "/posts/"+post.ID.Hex(), models.Post{Title: "Hello", Content: "Second draft", Version: 1})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	// Saving without changes is not an edit
+	recorder = sendPost(router, "PUT", "/posts/"+post.ID.Hex(), models.Post{Title: "Hello", Content: "Second draft", Version: 2})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = serve(router, "GET", "/posts/"+post.ID.Hex()+"/revisions")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var page models.RevisionPage
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, 2, page.Data[0].Number)
+	assert.Equal(t, 1, page.Data[1].Number)
+
+	revision := getRevision(t, router, post, 2)
+	assert.Equal(t, "alice", revision.Editor)
+	assert.Equal(t, "Second draft", revision.Content)
+	assert.Equal(t, []models.FieldChange{{Field: models.FieldContent, From: "First draft", To: "Second draft"}}, revision.Changes)
+
+	// Restoring puts the old content back and records it as a new revision
+	recorder = serve(router, "POST", "/posts/"+post.ID.Hex()+"/revisions/1/restore")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var restored models.Post
+	json.Unmarshal(recorder.Body.Bytes(), &restored)
+	assert.Equal(t, "First draft", restored.Content)
+	assert.True(t, restored.Edited)
+	assert.Equal(t, int64(4), restored.Version)
+
+	revision = getRevision(t, router, post, 3)
+	assert.Equal(t, 1, revision.RestoredFrom)
+	assert.Equal(t, "First draft", revision.Content)
+
+	recorder = serve(router, "GET", "/posts/"+post.ID.Hex()+"/revisions/9")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = serve(router, "GET", "/posts/"+post.ID.Hex()+"/revisions/first")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRevisionsOfOlderPost(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	router := newRevisionRouter(repos, "alice")
+
+	// A post written before revisions were kept starts its history at the first edit
+	recorder := sendPost(router, "PUT", "/posts/"+post.ID.Hex(), models.Post{Title: "Renamed", Content: post.Content, Version: post.Version})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	original := getRevision(t, router, post, 1)
+	assert.Equal(t, post.Title, original.Title)
+	assert.Equal(t, "alice", original.Editor)
+	edit := getRevision(t, router, post, 2)
+	assert.Equal(t, []models.FieldChange{{Field: models.FieldTitle, From: post.Title, To: "Renamed"}}, edit.Changes)
+}
+
+func TestRestoreRevisionByOtherUser(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	insertTestUser(repos, "bob", models.RoleUser)
+	sendPost(newRevisionRouter(repos, "alice"), "PUT", "/posts/"+post.ID.Hex(), models.Post{Title: "Renamed", Content: post.Content, Version: post.Version})
+
+	recorder := serve(newRevisionRouter(repos, "bob"), "POST", "/posts/"+post.ID.Hex()+"/revisions/1/restore")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	stored, _ := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.Equal(t, "Renamed", stored.Title)
+}
+
+func TestRestoreRevisionIfMatch(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	post := insertTestPost(repos, insertTestUser(repos, "alice", models.RoleUser))
+	router := newRevisionRouter(repos, "alice")
+	sendPost(router, "PUT", "/posts/"+post.ID.Hex(), models.Post{Title: "Renamed", Content: post.Content, Version: post.Version})
+	path := "/posts/" + post.ID.Hex() + "/revisions/1/restore"
+
+	recorder := serveIfMatch(router, "POST", path, `"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	recorder = serveIfMatch(router, "POST", path, `"2"`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	stored, _ := repos.Posts.FindByID(context.TODO(), post.ID)
+	assert.Equal(t, post.Title, stored.Title)
+	assert.Equal(t, int64(3), stored.Version)
+}
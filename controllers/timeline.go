@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+	"github.com/VisarutJDev/social-media-api/timeline"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimelineController serves personalized timelines
+type TimelineController struct {
+	Timeline  timeline.Strategy
+	Reactions repositories.ReactionRepository
+	Users     repositories.UserRepository
+}
+
+func NewTimelineController(strategy timeline.Strategy, reactions repositories.ReactionRepository, users repositories.UserRepository) *TimelineController {
+	return &TimelineController{Timeline: strategy, Reactions: reactions, Users: users}
+}
+
+// GetHomeTimeline godoc
+//
+//	@Summary		Home Timeline
+//	@Description	Get posts from the accounts the caller follows and their own, newest first
+//	@ID				GetHomeTimeline
+//	@Tags			timeline
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			limit	query		int				false	"page size, at most 100"	default(20)
+//	@Param			cursor	query		string			false	"next_cursor or prev_cursor from a previous page"
+//	@Success		200		{object}	models.PostPage	"OK"
+//	@Failure		400		{object}	models.Response	"Bad Request"
+//	@Failure		401		{object}	models.Response	"Unauthorized"
+//	@Failure		500		{object}	models.Response	"Internal Server Error"
+//	@Router			/timeline/home [get]
+func (tc *TimelineController) GetHomeTimeline(c *gin.Context) {
+	page, err := parsePage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	user, err := currentUser(c, tc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	posts, err := tc.Timeline.Home(c.Request.Context(), user.ID, fetchPage(page))
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	posts, hasMore := trimPage(page, posts)
+	if err := setViewerReactions(c.Request.Context(), tc.Reactions, user.ID, posts); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	var first, last primitive.ObjectID
+	if len(posts) > 0 {
+		first, last = posts[0].ID, posts[len(posts)-1].ID
+	}
+	c.JSON(http.StatusOK, models.PostPage{
+		Data:       posts,
+		Pagination: newPagination(page, hasMore, first, last),
+	})
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+	"github.com/VisarutJDev/social-media-api/timeline"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHomeTimeline(t *testing.T) {
+	for _, name := range []string{timeline.FanOutOnRead, timeline.FanOutOnWrite} {
+		t.Run(name, func(t *testing.T) {
+			repos := repositories.NewMemoryRepositories()
+			strategy, err := timeline.New(name, repos)
+			assert.NoError(t, err)
+			postController := NewPostController(repos.Posts, repos.Comments, repos.Reactions, repos.Revisions, repos.Users, strategy, testRetention)
+			timelineController := NewTimelineController(strategy, repos.Reactions, repos.Users)
+			for _, username := range []string{"alice", "bob", "carol"} {
+				insertTestUser(repos, username, models.RoleUser)
+			}
+			// alice follows bob but not carol
+			serve(newFollowRouter(repos, "alice"), "POST", "/users/bob/follow")
+
+			createPost := func(username string, title string) {
+				router := newTestRouter()
+				router.POST("/posts", withUser(username), postController.CreatePost)
+				jsonValue, _ := json.Marshal(models.Post{Title: title, Content: title})
+				req, _ := http.NewRequest("POST", "/posts", bytes.NewBuffer(jsonValue))
+				req.Header.Set("Content-Type", "application/json")
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, req)
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+			}
+			createPost("bob", "bob 1")
+			createPost("carol", "carol 1")
+			createPost("alice", "alice 1")
+			createPost("bob", "bob 2")
+
+			router := newTestRouter()
+			router.GET("/timeline/home", withUser("alice"), timelineController.GetHomeTimeline)
+
+			recorder := serve(router, "GET", "/timeline/home?limit=2")
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			var page models.PostPage
+			err = json.Unmarshal(recorder.Body.Bytes(), &page)
+			assert.NoError(t, err)
+			assert.Len(t, page.Data, 2)
+			assert.Equal(t, "bob 2", page.Data[0].Title)
+			assert.Equal(t, "alice 1", page.Data[1].Title)
+			assert.True(t, page.Pagination.HasMore)
+
+			recorder = serve(router, "GET", "/timeline/home?limit=2&cursor="+page.Pagination.NextCursor)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			json.Unmarshal(recorder.Body.Bytes(), &page)
+			assert.Len(t, page.Data, 1)
+			assert.Equal(t, "bob 1", page.Data[0].Title)
+			assert.False(t, page.Pagination.HasMore)
+
+			// Posts in the trash drop out of the timeline
+			err = repos.Posts.SoftDelete(context.TODO(), page.Data[0].ID, page.Data[0].Version, "bob", models.Now())
+			assert.NoError(t, err)
+			recorder = serve(router, "GET", "/timeline/home")
+			json.Unmarshal(recorder.Body.Bytes(), &page)
+			assert.Len(t, page.Data, 2)
+			assert.Equal(t, "bob 2", page.Data[0].Title)
+			assert.Equal(t, "alice 1", page.Data[1].Title)
+		})
+	}
+}
+
+func TestHomeTimelineSkipsTrash(t *testing.T) {
+	for _, name := range []string{timeline.FanOutOnRead, timeline.FanOutOnWrite} {
+		t.Run(name, func(t *testing.T) {
+			repos := repositories.NewMemoryRepositories()
+			strategy, err := timeline.New(name, repos)
+			assert.NoError(t, err)
+			postController := NewPostController(repos.Posts, repos.Comments, repos.Reactions, repos.Revisions, repos.Users, strategy, testRetention)
+			timelineController := NewTimelineController(strategy, repos.Reactions, repos.Users)
+			insertTestUser(repos, "alice", models.RoleUser)
+			insertTestUser(repos, "bob", models.RoleUser)
+			serve(newFollowRouter(repos, "alice"), "POST", "/users/bob/follow")
+
+			router := newTestRouter()
+			router.Use(withUser("bob"))
+			router.POST("/posts", postController.CreatePost)
+			router.DELETE("/posts/:id", postController.DeletePost)
+			router.POST("/posts/:id/restore", postController.RestorePost)
+			router.GET("/timeline/home", withUser("alice"), timelineController.GetHomeTimeline)
+			posts := make([]models.Post, 5)
+			for i := range posts {
+				recorder := sendPost(router, "POST", "/posts", models.Post{Title: "bob " + strconv.Itoa(i+1), Content: "Content"})
+				json.Unmarshal(recorder.Body.Bytes(), &posts[i])
+			}
+			home := func(cursor string) models.PostPage {
+				recorder := serve(router, "GET", "/timeline/home?limit=2&cursor="+cursor)
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				var page models.PostPage
+				json.Unmarshal(recorder.Body.Bytes(), &page)
+				return page
+			}
+			titles := func(page models.PostPage) []string {
+				titles := []string{}
+				for _, post := range page.Data {
+					titles = append(titles, post.Title)
+				}
+				return titles
+			}
+
+			// The whole first page goes to the trash
+			for _, post := range posts[3:] {
+				recorder := serveIfMatch(router, "DELETE", "/posts/"+post.ID.Hex(), `"1"`)
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			}
+			page := home("")
+			assert.Equal(t, []string{"bob 3", "bob 2"}, titles(page))
+			assert.True(t, page.Pagination.HasMore)
+			page = home(page.Pagination.NextCursor)
+			assert.Equal(t, []string{"bob 1"}, titles(page))
+			assert.False(t, page.Pagination.HasMore)
+
+			// A restored post is back in place
+			recorder := serve(router, "POST", "/posts/"+posts[3].ID.Hex()+"/restore")
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			page = home("")
+			assert.Equal(t, []string{"bob 4", "bob 3"}, titles(page))
+			assert.True(t, page.Pagination.HasMore)
+		})
+	}
+}
+
+func TestHomeTimelineAfterUnfollow(t *testing.T) {
+	for _, name := range []string{timeline.FanOutOnRead, timeline.FanOutOnWrite} {
+		t.Run(name, func(t *testing.T) {
+			repos := repositories.NewMemoryRepositories()
+			strategy, err := timeline.New(name, repos)
+			assert.NoError(t, err)
+			postController := NewPostController(repos.Posts, repos.Comments, repos.Reactions, repos.Revisions, repos.Users, strategy, testRetention)
+			followController := NewFollowController(repos.Follows, repos.Users, strategy)
+			timelineController := NewTimelineController(strategy, repos.Reactions, repos.Users)
+			insertTestUser(repos, "alice", models.RoleUser)
+			insertTestUser(repos, "bob", models.RoleUser)
+
+			router := newTestRouter()
+			router.Use(withUser("alice"))
+			router.POST("/users/:username/follow", followController.Follow)
+			router.DELETE("/users/:username/follow", followController.Unfollow)
+			router.GET("/timeline/home", timelineController.GetHomeTimeline)
+			router.POST("/posts", postController.CreatePost)
+			bobRouter := newTestRouter()
+			bobRouter.POST("/posts", withUser("bob"), postController.CreatePost)
+
+			serve(router, "POST", "/users/bob/follow")
+			sendPost(bobRouter, "POST", "/posts", models.Post{Title: "bob 1", Content: "Content"})
+			sendPost(router, "POST", "/posts", models.Post{Title: "alice 1", Content: "Content"})
+			var page models.PostPage
+			json.Unmarshal(serve(router, "GET", "/timeline/home").Body.Bytes(), &page)
+			assert.Len(t, page.Data, 2)
+
+			// Only the accounts alice still follows are left
+			recorder := serve(router, "DELETE", "/users/bob/follow")
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			json.Unmarshal(serve(router, "GET", "/timeline/home").Body.Bytes(), &page)
+			assert.Len(t, page.Data, 1)
+			assert.Equal(t, "alice 1", page.Data[0].Title)
+		})
+	}
+}
+
+func TestHomeTimelineSkipsStaleEntries(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	strategy := timeline.NewFanOutOnWrite(repos.Posts, repos.Follows, repos.Timelines)
+	postController := NewPostController(repos.Posts, repos.Comments, repos.Reactions, repos.Revisions, repos.Users, strategy, testRetention)
+	timelineController := NewTimelineController(strategy, repos.Reactions, repos.Users)
+	insertTestUser(repos, "alice", models.RoleUser)
+
+	router := newTestRouter()
+	router.Use(withUser("alice"))
+	router.POST("/posts", postController.CreatePost)
+	router.GET("/timeline/home", timelineController.GetHomeTimeline)
+	posts := make([]models.Post, 6)
+	for i := range posts {
+		recorder := sendPost(router, "POST", "/posts", models.Post{Title: "alice " + strconv.Itoa(i+1), Content: "Content"})
+		json.Unmarshal(recorder.Body.Bytes(), &posts[i])
+	}
+	home := func(cursor string) models.PostPage {
+		var page models.PostPage
+		json.Unmarshal(serve(router, "GET", "/timeline/home?limit=2&cursor="+cursor).Body.Bytes(), &page)
+		return page
+	}
+	titles := func(page models.PostPage) []string {
+		titles := []string{}
+		for _, post := range page.Data {
+			titles = append(titles, post.Title)
+		}
+		return titles
+	}
+
+	// Posts removed between reading the timeline and its posts leave their entries behind
+	for _, i := range []int{4, 3, 1} {
+		repos.Posts.Delete(context.TODO(), posts[i].ID)
+	}
+	page := home("")
+	assert.Equal(t, []string{"alice 6", "alice 3"}, titles(page))
+	assert.True(t, page.Pagination.HasMore)
+	last := home(page.Pagination.NextCursor)
+	assert.Equal(t, []string{"alice 1"}, titles(last))
+	assert.False(t, last.Pagination.HasMore)
+
+	// Walking back fills the page the same way
+	page = home(last.Pagination.PrevCursor)
+	assert.Equal(t, []string{"alice 6", "alice 3"}, titles(page))
+}
+
+func TestUnknownTimelineStrategy(t *testing.T) {
+	_, err := timeline.New("fanout_sideways", repositories.NewMemoryRepositories())
+	assert.Error(t, err)
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/auth"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// TokenController issues, rotates and revokes tokens
+type TokenController struct {
+	Keys          *auth.KeySet
+	RefreshTokens repositories.RefreshTokenRepository
+	Denylist      repositories.DenylistRepository
+	Users         repositories.UserRepository
+}
+
+func NewTokenController(keys *auth.KeySet, refreshTokens repositories.RefreshTokenRepository, denylist repositories.DenylistRepository, users repositories.UserRepository) *TokenController {
+	return &TokenController{Keys: keys, RefreshTokens: refreshTokens, Denylist: denylist, Users: users}
+}
+
+// newFamilyID starts a new refresh token family, one per login
+func newFamilyID() string {
+	return primitive.NewObjectID().Hex()
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issue signs an access token for user and stores a new refresh token in familyID
+func (tc *TokenController) issue(ctx context.Context, user models.User, familyID string) (models.AuthResponse, error) {
+	now := time.Now()
+	jti, err := randomToken()
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+	claims := &auth.Claims{
+		Username: user.Username,
+		Role:     user.EffectiveRole(),
+		FamilyID: familyID,
+		StandardClaims: jwt.StandardClaims{
+			Id:        jti,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(AccessTokenTTL).Unix(),
+		},
+	}
+	tokenString, err := tc.Keys.Sign(claims)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+	err = tc.RefreshTokens.Create(ctx, models.RefreshToken{
+		ID:        primitive.NewObjectID(),
+		Hash:      hashToken(refreshToken),
+		FamilyID:  familyID,
+		Username:  user.Username,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+	})
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	return models.AuthResponse{
+		Token:        tokenString,
+		ExpiresIn:    int64(AccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Refresh godoc
+//
+//	@Summary		Refresh token
+//	@Description	Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once; reusing one revokes every token of its login.
+//	@ID				RefreshToken
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			refreshInput	body		models.RefreshInput	true	"refresh token"
+//	@Success		200				{object}	models.AuthResponse	"OK"
+//	@Failure		400				{object}	models.Response		"Bad Request"
+//	@Failure		401				{object}	models.Response		"Unauthorized"
+//	@Failure		403				{object}	models.Response		"Forbidden"
+//	@Failure		422				{object}	models.Response		"Unprocessable Entity"
+//	@Failure		429				{object}	models.Response		"Too Many Requests"
+//	@Failure		500				{object}	models.Response		"Internal Server Error"
+//	@Router			/token/refresh [post]
+func (tc *TokenController) Refresh(c *gin.Context) {
+	var input models.RefreshInput
+	if err := bindJSON(c, &input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	stored, err := tc.RefreshTokens.Use(ctx, hashToken(input.RefreshToken))
+	if errors.Is(err, repositories.ErrNotFound) {
+		c.Error(apperrors.Unauthorized("Invalid refresh token"))
+		return
+	}
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	if stored.RevokedAt != nil {
+		c.Error(apperrors.Unauthorized("Refresh token has been revoked"))
+		return
+	}
+	if stored.UsedAt != nil {
+		// A rotated token came back, so it may have been stolen: end the whole login
+		if err := tc.RefreshTokens.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+		c.Error(apperrors.Unauthorized("Refresh token has already been used"))
+		return
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		c.Error(apperrors.Unauthorized("Refresh token has expired"))
+		return
+	}
+
+	// The new access token carries the user's current role
+	user, err := tc.Users.FindByUsername(ctx, stored.Username)
+	if errors.Is(err, repositories.ErrNotFound) {
+		c.Error(apperrors.Unauthorized("User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	if user.Suspended {
+		c.Error(errSuspended)
+		return
+	}
+
+	response, err := tc.issue(ctx, user, stored.FamilyID)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Logout godoc
+//
+//	@Summary		Logout
+//	@Description	Revoke the access token and every refresh token of the login it belongs to
+//	@ID				Logout
+//	@Tags			user
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	models.Response	"OK"
+//	@Failure		401	{object}	models.Response	"Unauthorized"
+//	@Failure		500	{object}	models.Response	"Internal Server Error"
+//	@Router			/logout [post]
+func (tc *TokenController) Logout(c *gin.Context) {
+	claims, ok := c.MustGet("claims").(*auth.Claims)
+	if !ok {
+		c.Error(apperrors.Unauthorized("Invalid token"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if claims.Id != "" {
+		if err := tc.Denylist.Deny(ctx, claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+	}
+	if claims.FamilyID != "" {
+		if err := tc.RefreshTokens.RevokeFamily(ctx, claims.FamilyID); err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Logged out successfully",
+	})
+}
+
+// JWKS godoc
+//
+//	@Summary		JSON Web Key Set
+//	@Description	Public keys that verify the access tokens issued by this API, selected by the token's "kid" header
+//	@ID				JWKS
+//	@Tags			user
+//	@Produce		json
+//	@Success		200	{object}	models.JWKS	"OK"
+//	@Router			/.well-known/jwks.json [get]
+func (tc *TokenController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, tc.Keys.JWKS())
+}
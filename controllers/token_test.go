@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/auth"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var testKeys = auth.NewHMACKeySet([]byte("your_secret_key"))
+
+// withClaims stands in for middlewares.AuthMiddleware by putting the claims of tokenString into the context
+func withClaims(tokenString string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := &auth.Claims{}
+		jwt.ParseWithClaims(tokenString, claims, testKeys.Keyfunc)
+		c.Set("username", claims.Username)
+		c.Set("claims", claims)
+		c.Next()
+	}
+}
+
+func postRefresh(router *gin.Engine, refreshToken string) *httptest.ResponseRecorder {
+	jsonValue, _ := json.Marshal(models.RefreshInput{RefreshToken: refreshToken})
+	req, _ := http.NewRequest("POST", "/token/refresh", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRefreshToken(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	tokenController := NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users)
+	login, err := tokenController.issue(context.TODO(), insertTestUser(repos, "testuser", models.RoleUser), newFamilyID())
+	assert.NoError(t, err)
+
+	router := newTestRouter()
+	router.POST("/token/refresh", tokenController.Refresh)
+
+	recorder := postRefresh(router, login.RefreshToken)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response models.AuthResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	assert.NotEqual(t, login.RefreshToken, response.RefreshToken)
+
+	// The rotated token works once more
+	recorder = postRefresh(router, response.RefreshToken)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	tokenController := NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users)
+	login, err := tokenController.issue(context.TODO(), insertTestUser(repos, "testuser", models.RoleUser), newFamilyID())
+	assert.NoError(t, err)
+
+	router := newTestRouter()
+	router.POST("/token/refresh", tokenController.Refresh)
+
+	recorder := postRefresh(router, login.RefreshToken)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var rotated models.AuthResponse
+	json.Unmarshal(recorder.Body.Bytes(), &rotated)
+
+	// Replaying the first token is rejected...
+	recorder = postRefresh(router, login.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// ...and revokes the token it was rotated into
+	recorder = postRefresh(router, rotated.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestRefreshTokenInvalid(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	tokenController := NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users)
+
+	router := newTestRouter()
+	router.POST("/token/refresh", tokenController.Refresh)
+
+	recorder := postRefresh(router, "not-a-refresh-token")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestLogout(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	tokenController := NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users)
+	login, err := tokenController.issue(context.TODO(), insertTestUser(repos, "testuser", models.RoleUser), newFamilyID())
+	assert.NoError(t, err)
+
+	router := newTestRouter()
+	router.POST("/token/refresh", tokenController.Refresh)
+	router.POST("/logout", withClaims(login.Token), tokenController.Logout)
+
+	req, _ := http.NewRequest("POST", "/logout", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The access token is on the denylist
+	claims := &auth.Claims{}
+	jwt.ParseWithClaims(login.Token, claims, testKeys.Keyfunc)
+	denied, err := repos.Denylist.IsDenied(context.TODO(), claims.Id)
+	assert.NoError(t, err)
+	assert.True(t, denied)
+
+	// The refresh token of the login no longer works
+	recorder = postRefresh(router, login.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestJWKS(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	tokenController := NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users)
+
+	router := newTestRouter()
+	router.GET("/.well-known/jwks.json", tokenController.JWKS)
+
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response models.JWKS
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	// The HMAC secret must never be published
+	assert.Empty(t, response.Keys)
+}
+
+func TestRefreshTokenSuspended(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	tokenController := NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users)
+	login, err := tokenController.issue(context.TODO(), insertTestUser(repos, "testuser", models.RoleUser), newFamilyID())
+	assert.NoError(t, err)
+	repos.Users.SetSuspended(context.TODO(), "testuser", true)
+
+	router := newTestRouter()
+	router.POST("/token/refresh", tokenController.Refresh)
+
+	recorder := postRefresh(router, login.RefreshToken)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// moveToTrash soft deletes post on behalf of user unless it changed since it was read.
+// Everything but its timeline entries stays until trash.Purger removes it for good.
+func (pc *PostController) moveToTrash(c *gin.Context, post models.Post, user models.User) error {
+	if err := pc.Posts.SoftDelete(c.Request.Context(), post.ID, post.Version, user.Username, models.Now()); err != nil {
+		// A concurrent request may have changed or deleted it first
+		return postWriteError(err)
+	}
+	// Reads skip trashed posts either way; leftover entries only cut timeline pages short
+	if err := pc.Timeline.PostDeleted(c.Request.Context(), post); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to remove post from timelines", "post_id", post.ID.Hex(), "error", err)
+	}
+	return nil
+}
+
+// GetTrash godoc
+//
+//	@Summary		Get Trash
+//	@Description	Get the caller's deleted posts, most recently deleted first, paginated with an opaque cursor. Posts are purged once the retention period ends.
+//	@ID				GetTrash
+//	@Tags			post
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			limit	query		int				false	"page size, at most 100"	default(20)
+//	@Param			cursor	query		string			false	"next_cursor or prev_cursor from a previous page"
+//	@Success		200		{object}	models.PostPage	"OK"
+//	@Failure		400		{object}	models.Response	"Bad Request"
+//	@Failure		401		{object}	models.Response	"Unauthorized"
+//	@Failure		500		{object}	models.Response	"Internal Server Error"
+//	@Router			/me/trash [get]
+func (pc *PostController) GetTrash(c *gin.Context) {
+	page, err := parsePageBy(c, models.SortDeletedAt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	user, err := currentUser(c, pc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	posts, err := pc.Posts.ListDeleted(c.Request.Context(), user.ID, fetchPage(page))
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	posts, hasMore := trimPage(page, posts)
+	var first, last pageCursor
+	if len(posts) > 0 {
+		first, last = postCursor(posts[0], page.SortBy), postCursor(posts[len(posts)-1], page.SortBy)
+	}
+	c.JSON(http.StatusOK, models.PostPage{
+		Data:       posts,
+		Pagination: newSortedPagination(page, hasMore, first, last),
+	})
+}
+
+// RestorePost godoc
+//
+//	@Summary		Restore Post
+//	@Description	Take a deleted post out of the trash before the retention period ends. Posts removed by someone else can only be restored by an admin.
+//	@ID				RestorePost
+//	@Tags			post
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string			true	"id of post to be restored"
+//	@Success		200	{object}	models.Post		"OK"
+//	@Failure		400	{object}	models.Response	"Bad Request"
+//	@Failure		401	{object}	models.Response	"Unauthorized"
+//	@Failure		403	{object}	models.Response	"Forbidden"
+//	@Failure		404	{object}	models.Response	"Not Found"
+//	@Failure		500	{object}	models.Response	"Internal Server Error"
+//	@Router			/posts/{id}/restore [post]
+func (pc *PostController) RestorePost(c *gin.Context) {
+	objID, err := pathID(c, "id", "post")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	user, err := currentUser(c, pc.Users)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	post, err := pc.Posts.FindDeleted(c.Request.Context(), objID)
+	if err != nil {
+		c.Error(lookupError(err, "Post not found in trash"))
+		return
+	}
+	if !canModifyPost(user, post) {
+		c.Error(apperrors.Forbidden("You are not allowed to restore this post"))
+		return
+	}
+	if post.DeletedBy != user.Username && user.Role != models.RoleAdmin {
+		c.Error(apperrors.Forbidden("Only an admin can restore a post removed by someone else"))
+		return
+	}
+	now := models.Now()
+	// The purge may not have run yet, but the post is already past saving
+	if now.Sub(*post.DeletedAt) >= pc.Retention {
+		c.Error(apperrors.NotFound("Post not found in trash"))
+		return
+	}
+	if err := pc.Posts.Restore(c.Request.Context(), objID, now); err != nil {
+		// A concurrent request may have restored or purged it first
+		c.Error(lookupError(err, "Post not found in trash"))
+		return
+	}
+	post.DeletedAt = nil
+	post.DeletedBy = ""
+	post.UpdatedAt = now
+	post.Version++
+	if err := pc.Timeline.PostCreated(c.Request.Context(), post); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to add post to timelines", "post_id", post.ID.Hex(), "error", err)
+	}
+	pc.renderPost(c, post)
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTrashRouter serves the post and trash routes acting as username
+func newTrashRouter(repos *repositories.Repositories, username string) *gin.Engine {
+	postController := newTestPostController(repos)
+
+	router := newTestRouter()
+	router.Use(withUser(username))
+	router.GET("/posts", postController.GetPosts)
+	router.GET("/posts/:id", postController.GetPost)
+	router.DELETE("/posts/:id", postController.DeletePost)
+	router.POST("/posts/:id/restore", postController.RestorePost)
+	router.GET("/me/trash", postController.GetTrash)
+	return router
+}
+
+func TestTrash(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	kept := insertTestPost(repos, alice)
+	deleted := insertTestPost(repos, alice)
+	router := newTrashRouter(repos, "alice")
+
+	recorder := serveIfMatch(router, "DELETE", "/posts/"+deleted.ID.Hex(), `"1"`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Deleted posts are gone from the lists and lookups
+	recorder = serve(router, "GET", "/posts/"+deleted.ID.Hex())
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = serveIfMatch(router, "DELETE", "/posts/"+deleted.ID.Hex(), `"1"`)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = serve(router, "GET", "/posts")
+	var page models.PostPage
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, kept.ID, page.Data[0].ID)
+
+	// but wait in the trash
+	recorder = serve(router, "GET", "/me/trash")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, deleted.ID, page.Data[0].ID)
+	assert.Equal(t, "alice", page.Data[0].DeletedBy)
+	assert.NotNil(t, page.Data[0].DeletedAt)
+
+	// The trash is the caller's own
+	insertTestUser(repos, "bob", models.RoleUser)
+	recorder = serve(newTrashRouter(repos, "bob"), "GET", "/me/trash")
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.Empty(t, page.Data)
+	recorder = serve(newTrashRouter(repos, "bob"), "POST", "/posts/"+deleted.ID.Hex()+"/restore")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = serve(router, "POST", "/posts/"+deleted.ID.Hex()+"/restore")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var restored models.Post
+	json.Unmarshal(recorder.Body.Bytes(), &restored)
+	assert.Equal(t, deleted.Title, restored.Title)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Empty(t, restored.DeletedBy)
+
+	recorder = serve(router, "GET", "/posts/"+deleted.ID.Hex())
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	recorder = serve(router, "POST", "/posts/"+deleted.ID.Hex()+"/restore")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestTrashPagination(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	start := models.Now()
+	var posts []models.Post
+	for i := range 3 {
+		post := insertTestPost(repos, alice)
+		// Deleted in the reverse order of creation
+		repos.Posts.SoftDelete(context.TODO(), post.ID, post.Version, "alice", start.Add(-time.Duration(i)*time.Minute))
+		posts = append(posts, post)
+	}
+	router := newTrashRouter(repos, "alice")
+
+	recorder := serve(router, "GET", "/me/trash?limit=2")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var page models.PostPage
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, posts[0].ID, page.Data[0].ID)
+	assert.Equal(t, posts[1].ID, page.Data[1].ID)
+	assert.True(t, page.Pagination.HasMore)
+
+	recorder = serve(router, "GET", "/me/trash?limit=2&cursor="+page.Pagination.NextCursor)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, posts[2].ID, page.Data[0].ID)
+	assert.False(t, page.Pagination.HasMore)
+
+	// Cursors of other lists do not continue the trash
+	insertTestPost(repos, alice)
+	insertTestPost(repos, alice)
+	recorder = serve(router, "GET", "/posts?limit=1")
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	recorder = serve(router, "GET", "/me/trash?cursor="+page.Pagination.NextCursor)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRestorePostPastRetention(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	alice := insertTestUser(repos, "alice", models.RoleUser)
+	post := insertTestPost(repos, alice)
+	repos.Posts.SoftDelete(context.TODO(), post.ID, post.Version, "alice", models.Now().Add(-testRetention))
+
+	recorder := serve(newTrashRouter(repos, "alice"), "POST", "/posts/"+post.ID.Hex()+"/restore")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	_, err := repos.Posts.FindDeleted(context.TODO(), post.ID)
+	assert.NoError(t, err)
+}
+
+func TestRestoreModeratedPost(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	bob := insertTestUser(repos, "bob", models.RoleUser)
+	insertTestUser(repos, "mod", models.RoleModerator)
+	insertTestUser(repos, "admin", models.RoleAdmin)
+	post := insertTestPost(repos, bob)
+
+	recorder := serveIfMatch(newAdminRouter(repos, "mod"), "DELETE", "/admin/posts/"+post.ID.Hex(), `"1"`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The author sees the removal but cannot undo it
+	recorder = serve(newTrashRouter(repos, "bob"), "GET", "/me/trash")
+	var page models.PostPage
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "mod", page.Data[0].DeletedBy)
+	recorder = serve(newTrashRouter(repos, "bob"), "POST", "/posts/"+post.ID.Hex()+"/restore")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = serve(newTrashRouter(repos, "admin"), "POST", "/posts/"+post.ID.Hex()+"/restore")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -1,25 +1,51 @@
 package controllers
 
 import (
-	"context"
+	"errors"
+	"math"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 
-	"github.com/VisarutJDev/social-media-api/database"
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/metrics"
 	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/ratelimit"
+	"github.com/VisarutJDev/social-media-api/repositories"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var JwtKey = []byte("your_secret_key")
+// UserController serves registration and login
+type UserController struct {
+	Users   repositories.UserRepository
+	Tokens  *TokenController
+	Lockout *ratelimit.Lockout // Locks accounts out after failed logins; nil never locks
+}
+
+func NewUserController(users repositories.UserRepository, tokens *TokenController, lockout *ratelimit.Lockout) *UserController {
+	return &UserController{Users: users, Tokens: tokens, Lockout: lockout}
+}
 
-type Claims struct {
-	Username string `json:"username"`
-	jwt.StandardClaims
+// lockoutKey names the failed logins of username, whether or not the account exists
+func lockoutKey(username string) string {
+	return "login:" + strings.ToLower(username)
+}
+
+// dummyPassword is a bcrypt hash at bcrypt.DefaultCost that logins of unknown
+// usernames are checked against, so they take as long as a wrong password
+var dummyPassword = []byte("$2a$10$PJkUi8Bzr0rJFHL808REbOZTj9HKEx6G2UfSaw/SPvtT1PCABhsVi")
+
+// loginFailed records a failed login of username and reports it
+func (uc *UserController) loginFailed(c *gin.Context, username string) {
+	metrics.Logins.WithLabelValues(metrics.Failed).Inc()
+	if _, err := uc.Lockout.Fail(c.Request.Context(), lockoutKey(username)); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	c.Error(apperrors.Unauthorized("Invalid username or password"))
 }
 
 // Register godoc
@@ -31,49 +57,43 @@ type Claims struct {
 //	@Accept			json
 //	@Produce		json
 //	@Param			user	body		models.User		true	"register"
-//	@Success		200		{object}	models.Response	"OK"
+//	@Success		201		{object}	models.Response	"Created"
 //	@Failure		400		{object}	models.Response	"Bad Request"
-//	@Failure		401		{object}	models.Response	"Unauthorized"
+//	@Failure		409		{object}	models.Response	"Conflict"
+//	@Failure		422		{object}	models.Response	"Unprocessable Entity"
+//	@Failure		429		{object}	models.Response	"Too Many Requests"
 //	@Failure		500		{object}	models.Response	"Internal Server Error"
 //	@Router			/register [post]
-func Register(c *gin.Context) {
+func (uc *UserController) Register(c *gin.Context) {
 	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Error: err.Error(),
-		})
-		// c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	err := database.Client.Database("social_media").Collection("users").FindOne(context.Background(), bson.M{"username": user.Username}).Decode(&user)
-	if err == nil {
-		c.JSON(http.StatusUnauthorized, models.Response{
-			Error: "Username already exist",
-		})
-		// c.JSON(http.StatusUnauthorized, gin.H{"error": "Username already exist"})
+	if err := bindJSON(c, &user); err != nil {
+		c.Error(err)
 		return
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: "Error while hashing password",
-		})
-		// c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while hashing password"})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 	user.Password = string(hashedPassword)
 	user.ID = primitive.NewObjectID()
-
-	_, err = database.Client.Database("social_media").Collection("users").InsertOne(context.Background(), user)
+	user.Role = models.RoleUser
+	user.Suspended = false
+	user.CreatedAt = models.Now()
+	user.UpdatedAt = user.CreatedAt
+
+	// The unique index on username rejects taken names, even when registered concurrently
+	err = uc.Users.Create(c.Request.Context(), user)
+	if errors.Is(err, repositories.ErrDuplicate) {
+		c.Error(apperrors.Conflict("Username already exist"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: "Error while creating user",
-		})
-		// c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating user"})
+		c.Error(apperrors.Internal(err))
 		return
 	}
+	metrics.Registrations.Inc()
 
 	c.JSON(http.StatusCreated, models.Response{
 		Message: "User registered successfully",
@@ -93,57 +113,64 @@ func Register(c *gin.Context) {
 //	@Success		200			{object}	models.AuthResponse	"OK"
 //	@Failure		400			{object}	models.Response		"Bad Request"
 //	@Failure		401			{object}	models.Response		"Unauthorized"
+//	@Failure		403			{object}	models.Response		"Forbidden"
+//	@Failure		422			{object}	models.Response		"Unprocessable Entity"
+//	@Failure		429			{object}	models.Response		"Too Many Requests"
 //	@Failure		500			{object}	models.Response		"Internal Server Error"
 //	@Router			/login [post]
-func Login(c *gin.Context) {
+func (uc *UserController) Login(c *gin.Context) {
 	var loginInput models.LoginInput
-	if err := c.ShouldBindJSON(&loginInput); err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Error: err.Error(),
-		})
-		// c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &loginInput); err != nil {
+		c.Error(err)
 		return
 	}
 
-	var user models.User
-	err := database.Client.Database("social_media").Collection("users").FindOne(context.Background(), bson.M{"username": loginInput.Username}).Decode(&user)
+	locked, err := uc.Lockout.Locked(c.Request.Context(), lockoutKey(loginInput.Username))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, models.Response{
-			Error: "Invalid username or password",
-		})
-		// c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	if locked > 0 {
+		metrics.Logins.WithLabelValues(metrics.Failed).Inc()
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.Seconds()))))
+		c.Error(apperrors.TooManyRequests("Too many failed logins, try again later"))
+		return
+	}
+
+	user, err := uc.Users.FindByUsername(c.Request.Context(), loginInput.Username)
+	if errors.Is(err, repositories.ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPassword, []byte(loginInput.Password))
+		uc.loginFailed(c, loginInput.Username)
+		return
+	}
+	if err != nil {
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginInput.Password))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, models.Response{
-			Error: "Invalid username or password",
-		})
-		// c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		uc.loginFailed(c, loginInput.Username)
+		return
+	}
+	if err := uc.Lockout.Succeed(c.Request.Context(), lockoutKey(loginInput.Username)); err != nil {
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &Claims{
-		Username: loginInput.Username,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-		},
+	// Only the right password learns that the account is suspended
+	if user.Suspended {
+		c.Error(errSuspended)
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(JwtKey)
+	response, err := uc.Tokens.issue(c.Request.Context(), user, newFamilyID())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Error: "Error while generating token",
-		})
-		// c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while generating token"})
+		c.Error(apperrors.Internal(err))
 		return
 	}
+	metrics.Logins.WithLabelValues(metrics.Succeeded).Inc()
 
-	c.JSON(http.StatusOK, models.AuthResponse{
-		Token: tokenString,
-	})
+	c.JSON(http.StatusOK, response)
 	// c.JSON(http.StatusOK, gin.H{"token": tokenString})
 }
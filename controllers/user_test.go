@@ -6,29 +6,27 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
-	"github.com/VisarutJDev/social-media-api/config"
-	"github.com/VisarutJDev/social-media-api/database"
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/metrics"
 	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/ratelimit"
+	"github.com/VisarutJDev/social-media-api/repositories"
 
-	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func init() {
-	config.LoadConfig("../config/config_unittest.json")
-	database.Connect(config.Config.MongoURI)
-}
-
 func TestRegister(t *testing.T) {
-	userCollection := database.Client.Database(config.Config.Database).Collection("users")
-	userCollection.Drop(context.TODO())
+	repos := repositories.NewMemoryRepositories()
+	userController := NewUserController(repos.Users, NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users), nil)
 
-	gin.SetMode(gin.TestMode)
-	router := gin.Default()
-	router.POST("/register", Register)
+	router := newTestRouter()
+	router.POST("/register", userController.Register)
 
 	user := models.User{
 		Username: "testuser",
@@ -48,20 +46,102 @@ func TestRegister(t *testing.T) {
 	assert.Equal(t, "User registered successfully", response["message"])
 }
 
+func TestRegisterDuplicate(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	userController := NewUserController(repos.Users, NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users), nil)
+	insertTestUser(repos, "testuser", models.RoleUser)
+
+	router := newTestRouter()
+	router.POST("/register", userController.Register)
+
+	jsonValue, _ := json.Marshal(models.User{Username: "testuser", Password: "password123"})
+	req, _ := http.NewRequest("POST", "/register", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	var response models.Response
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, apperrors.CodeConflict, response.Code)
+}
+
+func TestRegisterValidation(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	userController := NewUserController(repos.Users, NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users), nil)
+
+	router := newTestRouter()
+	router.POST("/register", userController.Register)
+
+	tests := []struct {
+		name   string
+		user   models.User
+		fields []models.FieldError
+	}{
+		{
+			name: "empty",
+			user: models.User{},
+			fields: []models.FieldError{
+				{Field: "username", Code: models.CodeRequired, Message: "is required"},
+				{Field: "password", Code: models.CodeRequired, Message: "is required"},
+			},
+		},
+		{
+			name: "too short",
+			user: models.User{Username: "ab", Password: "short"},
+			fields: []models.FieldError{
+				{Field: "username", Code: models.CodeTooShort, Message: "must be at least 3 characters"},
+				{Field: "password", Code: models.CodeTooShort, Message: "must be at least 8 characters"},
+			},
+		},
+		{
+			name: "invalid characters",
+			user: models.User{Username: "john doe!", Password: "password123"},
+			fields: []models.FieldError{
+				{Field: "username", Code: models.CodeInvalidCharacters, Message: "may only contain letters, digits and underscores"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonValue, _ := json.Marshal(tt.user)
+			req, _ := http.NewRequest("POST", "/register", bytes.NewBuffer(jsonValue))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			var response models.Response
+			err := json.Unmarshal(recorder.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, apperrors.CodeValidation, response.Code)
+			assert.Equal(t, tt.fields, response.Fields)
+		})
+	}
+
+	// A body that is not JSON at all has no fields to report
+	req, _ := http.NewRequest("POST", "/register", bytes.NewBufferString("{"))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{"error":"Invalid request body","code":"bad_request"}`, recorder.Body.String())
+}
+
 func TestLogin(t *testing.T) {
-	userCollection := database.Client.Database(config.Config.Database).Collection("users")
-	userCollection.Drop(context.TODO())
+	repos := repositories.NewMemoryRepositories()
+	userController := NewUserController(repos.Users, NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users), nil)
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 	testUser := models.User{
 		Username: "testuser",
 		Password: string(hashedPassword),
 	}
-	userCollection.InsertOne(context.TODO(), testUser)
+	repos.Users.Create(context.TODO(), testUser)
 
-	gin.SetMode(gin.TestMode)
-	router := gin.Default()
-	router.POST("/login", Login)
+	router := newTestRouter()
+	router.POST("/login", userController.Login)
 
 	loginInput := models.LoginInput{
 		Username: "testuser",
@@ -75,8 +155,127 @@ func TestLogin(t *testing.T) {
 	router.ServeHTTP(recorder, req)
 
 	assert.Equal(t, http.StatusOK, recorder.Code)
-	var response map[string]string
+	var response models.AuthResponse
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.NotEmpty(t, response["token"])
+	assert.NotEmpty(t, response.Token)
+	assert.NotEmpty(t, response.RefreshToken)
+}
+
+func TestDummyPassword(t *testing.T) {
+	// Unknown usernames only take as long as a wrong password at the same cost
+	cost, err := bcrypt.Cost(dummyPassword)
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+func TestLoginMetrics(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	userController := NewUserController(repos.Users, NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users), nil)
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	repos.Users.Create(context.TODO(), models.User{Username: "testuser", Password: string(hashedPassword)})
+
+	router := newTestRouter()
+	router.POST("/login", userController.Login)
+
+	succeeded := metrics.Logins.WithLabelValues(metrics.Succeeded)
+	failed := metrics.Logins.WithLabelValues(metrics.Failed)
+	succeededBefore, failedBefore := testutil.ToFloat64(succeeded), testutil.ToFloat64(failed)
+
+	for _, input := range []models.LoginInput{
+		{Username: "testuser", Password: "wrongpassword"},
+		{Username: "nobody", Password: "password123"},
+		{Username: "testuser", Password: "password123"},
+	} {
+		jsonValue, _ := json.Marshal(input)
+		req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, succeededBefore+1, testutil.ToFloat64(succeeded))
+	assert.Equal(t, failedBefore+2, testutil.ToFloat64(failed))
+}
+
+func TestLoginLockout(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	lockout := ratelimit.NewLockout(ratelimit.NewMemoryStore(), 2, time.Minute, time.Hour, 15*time.Minute)
+	userController := NewUserController(repos.Users, NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users), lockout)
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	repos.Users.Create(context.TODO(), models.User{Username: "testuser", Password: string(hashedPassword)})
+
+	router := newTestRouter()
+	router.POST("/login", userController.Login)
+	login := func(username string, password string) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(models.LoginInput{Username: username, Password: password})
+		req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, login("testuser", "wrongpassword").Code)
+	assert.Equal(t, http.StatusUnauthorized, login("testuser", "wrongpassword").Code)
+
+	// Even the right password is refused while the account is locked
+	recorder := login("TestUser", "password123")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+	var response models.Response
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Equal(t, apperrors.CodeRateLimited, response.Code)
+
+	// Unknown accounts are locked the same way, so lockouts do not reveal which exist
+	login("nobody", "password123")
+	login("nobody", "password123")
+	assert.Equal(t, http.StatusTooManyRequests, login("nobody", "password123").Code)
+
+	// Another account is unaffected, and a successful login clears its failures
+	repos.Users.Create(context.TODO(), models.User{Username: "other", Password: string(hashedPassword)})
+	assert.Equal(t, http.StatusUnauthorized, login("other", "wrongpassword").Code)
+	assert.Equal(t, http.StatusOK, login("other", "password123").Code)
+	assert.Equal(t, http.StatusUnauthorized, login("other", "wrongpassword").Code)
+	assert.Equal(t, http.StatusOK, login("other", "password123").Code)
+}
+
+func TestLoginLockoutConcurrent(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	lockout := ratelimit.NewLockout(ratelimit.NewMemoryStore(), 3, time.Minute, time.Hour, 15*time.Minute)
+	userController := NewUserController(repos.Users, NewTokenController(testKeys, repos.RefreshTokens, repos.Denylist, repos.Users), lockout)
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	repos.Users.Create(context.TODO(), models.User{Username: "testuser", Password: string(hashedPassword)})
+
+	router := newTestRouter()
+	router.POST("/login", userController.Login)
+	login := func(password string) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(models.LoginInput{Username: "testuser", Password: password})
+		req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// In a burst of wrong passwords, every one that is checked counts
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	refused := 0
+	for i := 0; i < lockout.Threshold+10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if login("wrongpassword").Code == http.StatusUnauthorized {
+				mu.Lock()
+				refused++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	attempts, _ := lockout.Store.Attempts(context.TODO(), lockoutKey("testuser"))
+	assert.Equal(t, refused, attempts.Failures)
+	assert.GreaterOrEqual(t, attempts.Failures, lockout.Threshold)
+	assert.Equal(t, http.StatusTooManyRequests, login("password123").Code)
 }
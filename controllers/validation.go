@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+)
+
+// usernamePattern is the charset allowed in usernames
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// The binding tags on the models use these rules, so they are registered
+// before any handler binds a request
+func init() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	// Report fields by the names clients send
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	validate.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return usernamePattern.MatchString(fl.Field().String())
+	})
+	validate.RegisterValidation("notblank", validators.NotBlank)
+}
+
+// bindJSON binds the request body to obj and validates it, returning an
+// apperrors.Validation listing the offending fields if that fails
+func bindJSON(c *gin.Context, obj any) error {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return nil
+	}
+	fields, ok := fieldErrors(err)
+	if !ok {
+		return apperrors.BadRequest("Invalid request body")
+	}
+	return apperrors.Validation(fields...)
+}
+
+// fieldErrors describes the fields behind err, when it is a validation failure
+func fieldErrors(err error) ([]models.FieldError, bool) {
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return nil, false
+	}
+	fields := make([]models.FieldError, len(invalid))
+	for i, fieldErr := range invalid {
+		fields[i] = fieldError(fieldErr)
+	}
+	return fields, true
+}
+
+// fieldError describes a failed binding rule in terms of the stable codes in models
+func fieldError(fieldErr validator.FieldError) models.FieldError {
+	field := models.FieldError{Field: fieldErr.Field()}
+	switch fieldErr.Tag() {
+	case "required", "notblank":
+		field.Code = models.CodeRequired
+		field.Message = "is required"
+	case "min":
+		field.Code = models.CodeTooShort
+		field.Message = fmt.Sprintf("must be at least %s characters", fieldErr.Param())
+	case "max":
+		field.Code = models.CodeTooLong
+		field.Message = fmt.Sprintf("must be at most %s characters", fieldErr.Param())
+	case "oneof":
+		field.Code = models.CodeInvalidChoice
+		field.Message = "must be one of " + strings.ReplaceAll(fieldErr.Param(), " ", ", ")
+	case "username":
+		field.Code = models.CodeInvalidCharacters
+		field.Message = "may only contain letters, digits and underscores"
+	default:
+		field.Code = models.CodeInvalid
+		field.Message = "is invalid"
+	}
+	return field
+}
@@ -2,11 +2,18 @@ package database
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
+	"os"
 	"time"
 
+	"github.com/VisarutJDev/social-media-api/metrics"
+	"github.com/VisarutJDev/social-media-api/tracing"
+
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 var Client *mongo.Client
@@ -15,12 +22,55 @@ func Connect(mongoURI string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	clientOptions := options.Client().ApplyURI(mongoURI)
+	monitor := chainMonitors(metrics.MongoMonitor(), tracing.MongoMonitor())
+	clientOptions := options.Client().ApplyURI(mongoURI).SetMonitor(monitor)
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("Failed to connect to MongoDB", "error", err)
+		os.Exit(1)
 	}
 
 	Client = client
-	log.Println("Connected to MongoDB!")
+	slog.Info("Connected to MongoDB")
+}
+
+// Disconnect closes the connections of Client, giving up when ctx is done
+func Disconnect(ctx context.Context) error {
+	if Client == nil {
+		return nil
+	}
+	if err := Client.Disconnect(ctx); err != nil {
+		return err
+	}
+	slog.Info("Disconnected from MongoDB")
+	return nil
+}
+
+// Ping checks that the primary of Client answers within ctx
+func Ping(ctx context.Context) error {
+	if Client == nil {
+		return errors.New("not connected")
+	}
+	return Client.Ping(ctx, readpref.Primary())
+}
+
+// chainMonitors returns a command monitor passing every event to each of monitors
+func chainMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				m.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				m.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				m.Failed(ctx, e)
+			}
+		},
+	}
 }
@@ -0,0 +1,115 @@
+// Package logging sets up the structured slog logger and carries the details
+// of the current request, which are added to every record logged with its context.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Formats of the log output
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Redacted replaces the values of sensitive attributes
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the parts of attribute names whose values are never logged
+var sensitiveKeys = []string{"password", "token", "authorization", "secret", "cookie"}
+
+// Setup makes the default slog logger, and with it the standard log package,
+// write records of at least level in format to w
+func Setup(w io.Writer, level string, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	handler, err := NewHandler(w, lvl, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// NewHandler returns a handler writing records of at least level in format to
+// w, with the request details added and sensitive values redacted
+func NewHandler(w io.Writer, level slog.Leveler, format string) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+	switch format {
+	case FormatJSON, "":
+		return contextHandler{slog.NewJSONHandler(w, opts)}, nil
+	case FormatText:
+		return contextHandler{slog.NewTextHandler(w, opts)}, nil
+	}
+	return nil, fmt.Errorf("unknown log format %q", format)
+}
+
+// redact hides the value of every attribute whose name looks sensitive
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		return attr
+	}
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, Redacted)
+		}
+	}
+	return attr
+}
+
+// Request holds the details of the request being served
+type Request struct {
+	ID       string
+	Route    string
+	Username string // Set once the caller is authenticated
+	Start    time.Time
+}
+
+type requestKey struct{}
+
+// WithRequest returns a copy of ctx carrying request
+func WithRequest(ctx context.Context, request *Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, request)
+}
+
+// RequestFrom returns the request carried by ctx, or nil
+func RequestFrom(ctx context.Context) *Request {
+	request, _ := ctx.Value(requestKey{}).(*Request)
+	return request
+}
+
+// contextHandler adds the request carried by the context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if request := RequestFrom(ctx); request != nil {
+		record.AddAttrs(
+			slog.String("request_id", request.ID),
+			slog.String("route", request.Route),
+		)
+		if request.Username != "" {
+			record.AddAttrs(slog.String("username", request.Username))
+		}
+		latency := time.Since(request.Start)
+		record.AddAttrs(slog.Float64("latency_ms", float64(latency.Microseconds())/1000))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
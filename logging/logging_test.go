@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, slog.LevelInfo, FormatJSON)
+	assert.NoError(t, err)
+	logger := slog.New(handler)
+
+	request := &Request{ID: "abc123", Route: "/login", Start: time.Now().Add(-time.Second)}
+	ctx := WithRequest(context.Background(), request)
+	request.Username = "alice"
+	logger.InfoContext(ctx, "Login",
+		"password", "password123",
+		"refresh_token", "eyJhbGciOi",
+		slog.Group("headers", "Authorization", "Bearer eyJhbGciOi"),
+		"post_id", "42",
+	)
+	logger.DebugContext(ctx, "Dropped below the level")
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "Login", record["msg"])
+	assert.Equal(t, "abc123", record["request_id"])
+	assert.Equal(t, "/login", record["route"])
+	assert.Equal(t, "alice", record["username"])
+	assert.GreaterOrEqual(t, record["latency_ms"], float64(1000))
+	assert.Equal(t, "42", record["post_id"])
+	// Sensitive values never reach the output, even inside groups
+	assert.Equal(t, Redacted, record["password"])
+	assert.Equal(t, Redacted, record["refresh_token"])
+	assert.Equal(t, map[string]any{"Authorization": Redacted}, record["headers"])
+	assert.NotContains(t, buf.String(), "eyJhbGciOi")
+	assert.NotContains(t, buf.String(), "Dropped")
+}
+
+func TestHandlerWithoutRequest(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, slog.LevelInfo, FormatText)
+	assert.NoError(t, err)
+	slog.New(handler).Info("Listening", "addr", ":8080")
+	assert.Contains(t, buf.String(), "msg=Listening addr=:8080")
+	assert.NotContains(t, buf.String(), "request_id")
+}
+
+func TestSetupInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	assert.ErrorContains(t, Setup(&buf, "verbose", FormatJSON), "invalid log level")
+	assert.ErrorContains(t, Setup(&buf, "info", "xml"), "unknown log format")
+}
@@ -1,14 +1,34 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/auth"
 	"github.com/VisarutJDev/social-media-api/config"
+	"github.com/VisarutJDev/social-media-api/controllers"
 	"github.com/VisarutJDev/social-media-api/database"
+	"github.com/VisarutJDev/social-media-api/logging"
+	"github.com/VisarutJDev/social-media-api/migrations"
+	"github.com/VisarutJDev/social-media-api/ratelimit"
+	"github.com/VisarutJDev/social-media-api/repositories"
 	"github.com/VisarutJDev/social-media-api/routes"
+	"github.com/VisarutJDev/social-media-api/server"
+	"github.com/VisarutJDev/social-media-api/timeline"
+	"github.com/VisarutJDev/social-media-api/tracing"
+	"github.com/VisarutJDev/social-media-api/trash"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/VisarutJDev/social-media-api/docs"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -38,13 +58,97 @@ func main() {
 	docs.SwaggerInfo.BasePath = "/"
 	docs.SwaggerInfo.Schemes = []string{"http"}
 
-	config.LoadConfig("config/config_local.json")
+	profile := flag.String("profile", "", "config profile: local, production or unittest (default $"+config.ProfileEnv+" or local)")
+	configDir := flag.String("config-dir", "config", "directory holding the config_<profile>.json files")
+	flag.Parse()
+	if err := config.Load(*configDir, config.ResolveProfile(*profile)); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := logging.Setup(os.Stdout, config.Config.LogLevel, config.Config.LogFormat); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	keys, err := auth.LoadKeySet(config.Config)
+	if err != nil {
+		fatal("Failed to load jwt keys", err)
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), config.Config.TracingExporter, config.Config.TracingEndpoint)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
 	database.Connect(config.Config.MongoURI)
+	db := database.Client.Database(config.Config.Database)
+	migrator := migrations.New(db, repositories.NewMongoMigrationRepository(db))
+	if flag.Arg(0) == "migrate" {
+		err := runMigrate(context.Background(), migrator, flag.Args()[1:], os.Stdout)
+		database.Disconnect(context.Background())
+		if err != nil {
+			fatal("Migration failed", err)
+		}
+		return
+	}
+	if config.Config.Migrate == config.MigrateUp {
+		if _, err := migrator.Up(context.Background(), 0); err != nil {
+			fatal("Failed to migrate database", err)
+		}
+	} else if pending, err := migrator.Pending(context.Background()); err != nil {
+		fatal("Failed to read migrations", err)
+	} else if len(pending) > 0 {
+		slog.Warn("Database migrations are pending, run the migrate subcommand", "pending", len(pending))
+	}
 
-	router := gin.Default()
+	// Requests are logged by middlewares.Logger
+	router := gin.New()
+	router.Use(gin.Recovery())
+	// Client addresses key the rate limits, so only believe known proxies
+	if err := router.SetTrustedProxies(config.Config.TrustedProxies); err != nil {
+		fatal("Invalid trusted proxies", err)
+	}
 	// router.Use(middlewares.TokenAuthMiddleware())
-	routes.InitRoutes(router)
+	repos := repositories.NewMongoRepositories(db)
+	strategy, err := timeline.New(config.Config.TimelineStrategy, repos)
+	if err != nil {
+		fatal("Failed to set up timelines", err)
+	}
+	health := controllers.NewHealthController(time.Duration(config.Config.ReadinessTimeout),
+		controllers.Dependency{Name: "mongo", Ping: database.Ping},
+	)
+	rateLimits := map[string]ratelimit.Limit{}
+	for group, limit := range config.Config.RateLimits {
+		rateLimits[group] = ratelimit.Limit{Requests: limit.Requests, Per: time.Duration(limit.Per)}
+	}
+	limitStore := ratelimit.NewMemoryStore()
+	lockout := config.Config.Lockout
+	routes.InitRoutes(router, repos, keys, strategy, routes.Options{
+		ReactionTypes:  config.Config.ReactionTypes,
+		Health:         health,
+		RateLimitStore: limitStore,
+		RateLimits:     rateLimits,
+		Lockout: ratelimit.NewLockout(limitStore, lockout.Threshold,
+			time.Duration(lockout.Base), time.Duration(lockout.Max), time.Duration(lockout.Window)),
+		TrashRetention: time.Duration(config.Config.TrashRetention),
+	})
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if config.Config.MetricsPath != config.MetricsOff {
+		router.GET(config.Config.MetricsPath, gin.WrapH(promhttp.Handler()))
+	}
+
+	if config.Config.TLSCertFile != "" {
+		docs.SwaggerInfo.Schemes = []string{"https"}
+	}
+
+	// SIGINT or SIGTERM drains in-flight requests and lets a running purge finish before Mongo is disconnected
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	purger := trash.NewPurger(repos, strategy, time.Duration(config.Config.TrashRetention))
+	waitPurger := purger.Start(ctx, time.Duration(config.Config.PurgeInterval))
+	srv := server.New(config.Config, router)
+	if err := server.ListenAndServe(ctx, srv, config.Config, waitPurger, database.Disconnect, shutdownTracing); err != nil {
+		fatal("Server stopped", err)
+	}
+}
 
-	router.Run(":8080")
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
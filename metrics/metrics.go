@@ -0,0 +1,80 @@
+// Package metrics holds the Prometheus collectors of the API. They are
+// registered with the default registry, which promhttp.Handler serves.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "social_media"
+
+// Outcomes of logins and Mongo commands
+const (
+	Succeeded = "succeeded"
+	Failed    = "failed"
+)
+
+// HTTP traffic, labeled by route template rather than raw path so that ids do
+// not explode the number of series
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to serve HTTP requests, by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests being served, by method and route.",
+	}, []string{"method", "route"})
+)
+
+// MongoCommandDuration times every command sent to MongoDB, see MongoMonitor
+var MongoCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "mongo_command_duration_seconds",
+	Help:      "Time of MongoDB commands, by command, collection and outcome.",
+	Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"command", "collection", "outcome"})
+
+// Business events
+var (
+	Registrations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "Users registered.",
+	})
+
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts, by outcome.",
+	}, []string{"outcome"})
+
+	PostsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "posts_created_total",
+		Help:      "Posts created.",
+	})
+
+	PostsPurged = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "posts_purged_total",
+		Help:      "Posts removed for good after their time in the trash.",
+	})
+)
+
+func init() {
+	// Export both outcomes from the start so rates work before the first failure
+	Logins.WithLabelValues(Succeeded)
+	Logins.WithLabelValues(Failed)
+}
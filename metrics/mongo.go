@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoMonitor returns a command monitor recording MongoCommandDuration. Pass
+// it to options.ClientOptions.SetMonitor.
+func MongoMonitor() *event.CommandMonitor {
+	// The collection is only named in the started event
+	var collections sync.Map
+	finished := func(e event.CommandFinishedEvent, outcome string) {
+		collection, _ := collections.LoadAndDelete(e.RequestID)
+		name, _ := collection.(string)
+		MongoCommandDuration.WithLabelValues(e.CommandName, name, outcome).Observe(e.Duration.Seconds())
+	}
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			collection, _ := e.Command.Lookup(e.CommandName).StringValueOK()
+			collections.Store(e.RequestID, collection)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finished(e.CommandFinishedEvent, Succeeded)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finished(e.CommandFinishedEvent, Failed)
+		},
+	}
+}
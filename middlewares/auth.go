@@ -1,12 +1,17 @@
 package middlewares
 
 import (
-	"net/http"
+	"errors"
+	"slices"
+	"strings"
 
-	"github.com/VisarutJDev/social-media-api/controllers"
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/auth"
+	"github.com/VisarutJDev/social-media-api/logging"
+	"github.com/VisarutJDev/social-media-api/repositories"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
 )
 
 // Example authentication without user/pass
@@ -22,41 +27,76 @@ import (
 // 	}
 // }
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware verifies the bearer token against keys, rejects tokens revoked
+// through denylist and tokens of suspended users. It sets the "username" and
+// the stored "role" of the caller.
+func AuthMiddleware(keys *auth.KeySet, denylist repositories.DenylistRepository, users repositories.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-
-		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token not provided"})
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			c.Error(apperrors.Unauthorized("Authorization token not provided"))
 			c.Abort()
 			return
 		}
 
-		tokenString = tokenString[len("Bearer "):]
-		claims := &controllers.Claims{}
-
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return controllers.JwtKey, nil
-		})
+		claims := &auth.Claims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc)
+		if err != nil || !token.Valid {
+			c.Error(apperrors.Unauthorized("Invalid token"))
+			c.Abort()
+			return
+		}
 
-		if err != nil {
-			if err == jwt.ErrSignatureInvalid {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token signature"})
+		if claims.Id != "" {
+			denied, err := denylist.IsDenied(c.Request.Context(), claims.Id)
+			if err != nil {
+				c.Error(apperrors.Internal(err))
 				c.Abort()
 				return
 			}
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Error while parsing token"})
+			if denied {
+				c.Error(apperrors.Unauthorized("Token has been revoked"))
+				c.Abort()
+				return
+			}
+		}
+
+		// The stored user decides, so suspensions and role changes apply at once
+		user, err := users.FindByUsername(c.Request.Context(), claims.Username)
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.Error(apperrors.Unauthorized("User not found"))
 			c.Abort()
 			return
 		}
-
-		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		if err != nil {
+			c.Error(apperrors.Internal(err))
+			c.Abort()
+			return
+		}
+		if user.Suspended {
+			c.Error(apperrors.Forbidden("Account is suspended"))
 			c.Abort()
 			return
 		}
 
 		c.Set("username", claims.Username)
+		c.Set("role", user.EffectiveRole())
+		c.Set("claims", claims)
+		if request := logging.RequestFrom(c.Request.Context()); request != nil {
+			request.Username = claims.Username
+		}
+		c.Next()
+	}
+}
+
+// RequireRole lets only callers holding one of roles through. Use it after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(roles, c.GetString("role")) {
+			c.Error(apperrors.Forbidden("You are not allowed to do this"))
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
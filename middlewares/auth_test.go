@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/auth"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// authRouter serves GET /admin behind AuthMiddleware and RequireRole(roles...)
+func authRouter(keys *auth.KeySet, repos *repositories.Repositories, roles ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/admin", AuthMiddleware(keys, repos.Denylist, repos.Users), RequireRole(roles...), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("role"))
+	})
+	return router
+}
+
+func bearer(t *testing.T, keys *auth.KeySet, username, role string) string {
+	token, err := keys.Sign(&auth.Claims{
+		Username:       username,
+		Role:           role,
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()},
+	})
+	assert.NoError(t, err)
+	return "Bearer " + token
+}
+
+func getAdmin(router *gin.Engine, authorization string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	req.Header.Set("Authorization", authorization)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRequireRole(t *testing.T) {
+	keys := auth.NewHMACKeySet([]byte("secret"))
+	repos := repositories.NewMemoryRepositories()
+	repos.Users.Create(context.TODO(), models.User{ID: primitive.NewObjectID(), Username: "alice", Role: models.RoleAdmin})
+	repos.Users.Create(context.TODO(), models.User{ID: primitive.NewObjectID(), Username: "bob", Role: models.RoleUser})
+	router := authRouter(keys, repos, models.RoleAdmin)
+
+	recorder := getAdmin(router, bearer(t, keys, "alice", models.RoleAdmin))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, models.RoleAdmin, recorder.Body.String())
+
+	// The stored role decides, not the one in the token
+	recorder = getAdmin(router, bearer(t, keys, "bob", models.RoleAdmin))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Accounts created before roles were stored are users
+	repos.Users.Create(context.TODO(), models.User{ID: primitive.NewObjectID(), Username: "carol"})
+	recorder = getAdmin(authRouter(keys, repos, models.RoleUser), bearer(t, keys, "carol", ""))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, models.RoleUser, recorder.Body.String())
+}
+
+func TestAuthMiddlewareSuspended(t *testing.T) {
+	keys := auth.NewHMACKeySet([]byte("secret"))
+	repos := repositories.NewMemoryRepositories()
+	repos.Users.Create(context.TODO(), models.User{ID: primitive.NewObjectID(), Username: "alice", Role: models.RoleAdmin, Suspended: true})
+	router := authRouter(keys, repos, models.RoleAdmin)
+
+	recorder := getAdmin(router, bearer(t, keys, "alice", models.RoleAdmin))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Account is suspended")
+
+	// Deleted users' tokens stop working too
+	recorder = getAdmin(router, bearer(t, keys, "carol", models.RoleAdmin))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler renders the last error attached with c.Error as a models.Response.
+// Errors outside the apperrors catalog are reported as internal errors.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+		var appErr *apperrors.Error
+		if !errors.As(last.Err, &appErr) {
+			appErr = apperrors.Internal(last.Err)
+		}
+		if appErr.Status >= 500 {
+			slog.ErrorContext(c.Request.Context(), "Request failed", "error", appErr)
+		}
+		c.JSON(appErr.Status, appErr.Response())
+	}
+}
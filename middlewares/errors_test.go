@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/conflict", func(c *gin.Context) {
+		c.Error(apperrors.Conflict("Username already exist"))
+	})
+	router.GET("/internal", func(c *gin.Context) {
+		c.Error(errors.New("connection refused"))
+	})
+	router.GET("/written", func(c *gin.Context) {
+		c.Error(errors.New("logged only"))
+		c.String(http.StatusOK, "OK")
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{path: "/conflict", status: http.StatusConflict, body: `{"error":"Username already exist","code":"conflict"}`},
+		// The cause of an internal error is never shown to clients
+		{path: "/internal", status: http.StatusInternalServerError, body: `{"error":"Internal server error","code":"internal_error"}`},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, tt.status, recorder.Code)
+		assert.JSONEq(t, tt.body, recorder.Body.String())
+	}
+
+	// A response the handler already wrote is left alone
+	req, _ := http.NewRequest("GET", "/written", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "OK", recorder.Body.String())
+}
@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the id that correlates a request with its log lines
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the ids accepted from clients
+const maxRequestIDLength = 128
+
+// RequestID takes the request id from the X-Request-ID header, or generates
+// one, echoes it back and puts it with the route in the request context for
+// the log lines of the request
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		request := &logging.Request{ID: id, Route: c.FullPath(), Start: time.Now()}
+		c.Request = c.Request.WithContext(logging.WithRequest(c.Request.Context(), request))
+		c.Next()
+	}
+}
+
+// validRequestID accepts short ids of printable ASCII so that clients cannot
+// forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Logger logs one line per request once it is served, at error level for
+// server errors and warning level for client errors. Use it after RequestID.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int("size", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.Last().Error()))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, logging.RequestFrom(c.Request.Context()).ID)
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		accepted bool
+	}{
+		{name: "accepted", header: "req-42", accepted: true},
+		{name: "generated", header: ""},
+		{name: "too long", header: strings.Repeat("a", 129)},
+		{name: "control characters", header: "req\n42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/ping", nil)
+			req.Header.Set(RequestIDHeader, tt.header)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			id := recorder.Header().Get(RequestIDHeader)
+			assert.Equal(t, id, recorder.Body.String())
+			if tt.accepted {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.Len(t, id, 32)
+			}
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler, _ := logging.NewHandler(&buf, slog.LevelInfo, logging.FormatJSON)
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(handler))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), Logger(), ErrorHandler())
+	router.POST("/posts/:id", func(c *gin.Context) {
+		// As AuthMiddleware does
+		logging.RequestFrom(c.Request.Context()).Username = "alice"
+		c.Error(apperrors.NotFound("Post not found"))
+	})
+
+	req, _ := http.NewRequest("POST", "/posts/42", strings.NewReader(`{"password":"password123"}`))
+	req.Header.Set(RequestIDHeader, "req-42")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "req-42", record["request_id"])
+	assert.Equal(t, "/posts/:id", record["route"])
+	assert.Equal(t, "/posts/42", record["path"])
+	assert.Equal(t, "alice", record["username"])
+	assert.Equal(t, float64(http.StatusNotFound), record["status"])
+	assert.Equal(t, "Post not found", record["error"])
+	assert.Contains(t, record, "latency_ms")
+	assert.NotContains(t, buf.String(), "password123")
+}
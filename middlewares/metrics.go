@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route
+const unmatchedRoute = "unmatched"
+
+// Metrics records the request count, latency and in-flight requests of every
+// route. Use it before ErrorHandler so the rendered error status is recorded.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		inFlight := metrics.HTTPRequestsInFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequests.WithLabelValues(method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics(), ErrorHandler())
+	router.GET("/things/:id", func(c *gin.Context) {
+		// The request is counted as in flight while it is served
+		inFlight := testutil.ToFloat64(metrics.HTTPRequestsInFlight.WithLabelValues("GET", "/things/:id"))
+		assert.Equal(t, float64(1), inFlight)
+		if c.Param("id") == "missing" {
+			c.Error(apperrors.NotFound("Thing not found"))
+			return
+		}
+		c.String(http.StatusOK, "OK")
+	})
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(apperrors.NotFound("Route not found"))
+	})
+
+	ok := metrics.HTTPRequests.WithLabelValues("GET", "/things/:id", "200")
+	notFound := metrics.HTTPRequests.WithLabelValues("GET", "/things/:id", "404")
+	unmatched := metrics.HTTPRequests.WithLabelValues("GET", "unmatched", "404")
+	okBefore, notFoundBefore, unmatchedBefore := testutil.ToFloat64(ok), testutil.ToFloat64(notFound), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/things/1", "/things/2", "/things/missing", "/nowhere"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Raw paths are folded into their route template
+	assert.Equal(t, okBefore+2, testutil.ToFloat64(ok))
+	// The status rendered by ErrorHandler is the one recorded
+	assert.Equal(t, notFoundBefore+1, testutil.ToFloat64(notFound))
+	assert.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.HTTPRequestsInFlight.WithLabelValues("GET", "/things/:id")))
+}
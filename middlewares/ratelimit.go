@@ -0,0 +1,120 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKey names the bucket a request is counted in, or "" to not count it
+type RateLimitKey func(c *gin.Context) string
+
+// maxPeekBytes bounds how much of a body ByUsername reads
+const maxPeekBytes = 64 << 10
+
+// ByIP counts requests per client address
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUsername counts requests per account: the authenticated caller, else the
+// username field of a JSON body as sent to /login and /register
+func ByUsername(c *gin.Context) string {
+	username := c.GetString("username")
+	if username == "" {
+		username = peekUsername(c)
+	}
+	if username == "" {
+		return ""
+	}
+	return "user:" + strings.ToLower(username)
+}
+
+// peekUsername reads the username field of the body, leaving the body for the handler
+func peekUsername(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	peeked, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPeekBytes))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), c.Request.Body), c.Request.Body}
+	if err != nil {
+		return ""
+	}
+	var body struct {
+		Username string `json:"username"`
+	}
+	json.Unmarshal(peeked, &body)
+	return body.Username
+}
+
+// RateLimit takes a token for every key of the request from the buckets of
+// group in store and rejects the request with 429 once any bucket is empty.
+// The RateLimit headers report the most constrained bucket.
+func RateLimit(store ratelimit.Store, group string, limit ratelimit.Limit, keys ...RateLimitKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limit.Enabled() {
+			c.Next()
+			return
+		}
+		var tightest *ratelimit.Result
+		for _, key := range keys {
+			name := key(c)
+			if name == "" {
+				continue
+			}
+			result, err := store.Take(c.Request.Context(), group+":"+name, limit)
+			if err != nil {
+				c.Error(apperrors.Internal(err))
+				c.Abort()
+				return
+			}
+			if tightest == nil || tighter(result, *tightest) {
+				tightest = &result
+			}
+		}
+		if tightest == nil {
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(tightest.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(tightest.Reset))
+		c.Header("RateLimit-Policy", strconv.Itoa(limit.Requests)+";w="+ceilSeconds(limit.Per))
+		if !tightest.Allowed {
+			c.Header("Retry-After", ceilSeconds(tightest.RetryAfter))
+			c.Error(apperrors.TooManyRequests("Too many requests, try again later"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// tighter reports whether a constrains the caller more than b
+func tighter(a ratelimit.Result, b ratelimit.Result) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+	return a.Remaining < b.Remaining
+}
+
+// ceilSeconds formats d as whole seconds, rounded up
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRateLimitRouter(store ratelimit.Store, limit ratelimit.Limit) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.POST("/login", RateLimit(store, "auth", limit, ByIP, ByUsername), func(c *gin.Context) {
+		// The handler still gets the whole body
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	return router
+}
+
+func postLogin(router *gin.Engine, ip string, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/login", strings.NewReader(body))
+	req.RemoteAddr = ip + ":12345"
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimit(t *testing.T) {
+	router := newRateLimitRouter(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 2, Per: time.Minute})
+
+	w := postLogin(router, "10.0.0.1", `{"username":"alice"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"username":"alice"}`, w.Body.String())
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+
+	w = postLogin(router, "10.0.0.1", `{"username":"bob"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	// The address is out of tokens
+	w = postLogin(router, "10.0.0.1", `{"username":"carol"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"Too many requests, try again later","code":"rate_limited"}`, w.Body.String())
+
+	// So is alice, whichever address she comes from
+	postLogin(router, "10.0.0.2", `{"username":"Alice"}`)
+	w = postLogin(router, "10.0.0.3", `{"username":"alice"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// Other callers are unaffected
+	w = postLogin(router, "10.0.0.4", `{"username":"dave"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	router := newRateLimitRouter(ratelimit.NewMemoryStore(), ratelimit.Limit{})
+	for i := 0; i < 5; i++ {
+		w := postLogin(router, "10.0.0.1", `{"username":"alice"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Tracing())
+	var handlerSpan trace.SpanContext
+	router.GET("/posts/:id", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Set("username", "alice")
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req, _ := http.NewRequest("GET", "/posts/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "GET /posts/:id", span.Name)
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+		// The span continues the caller's trace
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+		// Handlers see it in the request context
+		assert.Equal(t, span.SpanContext.SpanID(), handlerSpan.SpanID())
+		assert.Equal(t, codes.Error, span.Status.Code)
+		assert.Contains(t, span.Attributes, attribute.String("http.route", "/posts/:id"))
+		assert.Contains(t, span.Attributes, attribute.Int("http.response.status_code", 500))
+		assert.Contains(t, span.Attributes, attribute.String("enduser.id", "alice"))
+	}
+}
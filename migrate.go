@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/migrations"
+)
+
+// runMigrate serves the migrate subcommand:
+//
+//	migrate up [version]  apply the pending migrations, up to version when given
+//	migrate down [steps]  revert the last steps migrations, one by default
+//	migrate status        list every migration and when it was applied
+func runMigrate(ctx context.Context, migrator *migrations.Migrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up [version] | down [steps] | status")
+	}
+	number := func(fallback int) (int, error) {
+		if len(args) < 2 {
+			return fallback, nil
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%s takes a number, got %q", args[0], args[1])
+		}
+		return n, nil
+	}
+
+	switch args[0] {
+	case "up":
+		target, err := number(0)
+		if err != nil {
+			return err
+		}
+		done, err := migrator.Up(ctx, target)
+		printMigrations(out, "Applied", done)
+		return err
+	case "down":
+		steps, err := number(1)
+		if err != nil {
+			return err
+		}
+		done, err := migrator.Down(ctx, steps)
+		printMigrations(out, "Reverted", done)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "%4d  %-25s  %s\n", status.Version, applied, status.Description)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown migrate command %q, want up, down or status", args[0])
+}
+
+func printMigrations(out io.Writer, verb string, done []migrations.Migration) {
+	if len(done) == 0 {
+		fmt.Fprintln(out, "Nothing to do")
+	}
+	for _, migration := range done {
+		fmt.Fprintf(out, "%s %d  %s\n", verb, migration.Version, migration.Description)
+	}
+}
@@ -0,0 +1,228 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// All are the migrations of this build. Append new ones with the next version
+// and never change one that has shipped.
+var All = []Migration{
+	indexMigration(1, "Index follows, timelines, comments and reactions",
+		// A single edge per pair, serving both list directions
+		collectionIndexes{"follows", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "followee_id", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "_id", Value: -1}}},
+		}},
+		// A post once per timeline, read newest first
+		collectionIndexes{"timelines", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "post_id", Value: -1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "post_id", Value: 1}}},
+		}},
+		// One thread level of a post, newest first
+		collectionIndexes{"comments", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "parent_id", Value: 1}, {Key: "_id", Value: -1}}},
+		}},
+		// A single reaction per user and post
+		collectionIndexes{"reactions", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "post_id", Value: 1}}},
+		}},
+	),
+	// ObjectIDs grow with time, so _id orders an author's posts by date
+	indexMigration(2, "Index posts by author and date",
+		collectionIndexes{"posts", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "_id", Value: -1}}},
+		}},
+	),
+	// Fails while duplicate usernames exist; remove them and run it again
+	indexMigration(3, "Make usernames unique",
+		collectionIndexes{"users", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
+		}},
+	),
+	{
+		Version:     4,
+		Description: "Backfill created_at and updated_at, and index posts by their times",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := backfillTimestamps(ctx, db, "posts", "users"); err != nil {
+				return err
+			}
+			return postTimeIndexes.Up(ctx, db)
+		},
+		// The backfilled times stay
+		Down: postTimeIndexes.Down,
+	},
+	// A number once per post, and a post's history newest first
+	indexMigration(5, "Index revisions",
+		collectionIndexes{"revisions", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "number", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "_id", Value: -1}}},
+		}},
+	),
+	// An author's trash most recently deleted first, and the oldest deletions for the purge
+	indexMigration(6, "Index deleted posts",
+		collectionIndexes{"posts", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "deleted_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		}},
+	),
+	{
+		Version:     7,
+		Description: "Start posts written before versioning at version 1",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("posts").UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"version": 1}})
+			return err
+		},
+		// The versions stay, since later writes have moved them on
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return nil
+		},
+	},
+	// A refresh token once per hash and a family revoked at once. Tokens leave
+	// both collections when they expire, since neither is honored past then.
+	indexMigration(8, "Index refresh tokens and expire revoked tokens",
+		collectionIndexes{"refresh_tokens", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "family_id", Value: 1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		}},
+		collectionIndexes{"denied_tokens", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		}},
+	),
+	{
+		Version:     9,
+		Description: "Give users created before roles the user role",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("users").UpdateMany(ctx, bson.M{"role": bson.M{"$in": bson.A{nil, ""}}}, bson.M{"$set": bson.M{"role": models.RoleUser}})
+			return err
+		},
+		// Users without a role are users, so unsetting it takes nothing away
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("users").UpdateMany(ctx, bson.M{"role": models.RoleUser}, bson.M{"$unset": bson.M{"role": ""}})
+			return err
+		},
+	},
+	{
+		Version:     10,
+		Description: "Backfill author_id of posts written before it was stored",
+		Up:          backfillAuthorIDs,
+		// The ids stay, since posts are owned by them
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return nil
+		},
+	},
+}
+
+// postTimeIndexes serve the sort options of GET /posts
+var postTimeIndexes = indexMigration(4, "",
+	collectionIndexes{"posts", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "edited_at", Value: -1}, {Key: "_id", Value: -1}}},
+	}},
+)
+
+// backfillTimestamps sets created_at and updated_at of the documents written
+// before they existed to the creation time held in their ObjectID
+func backfillTimestamps(ctx context.Context, db *mongo.Database, collections ...string) error {
+	created := bson.D{{Key: "$toDate", Value: "$_id"}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "created_at", Value: created}, {Key: "updated_at", Value: created}}}},
+	}
+	for _, collection := range collections {
+		_, err := db.Collection(collection).UpdateMany(ctx, bson.M{"created_at": bson.M{"$exists": false}}, update)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillAuthorIDs sets the author_id of the posts written before it was
+// stored to the id of the user named by their author. Posts of users that no
+// longer exist are left alone.
+func backfillAuthorIDs(ctx context.Context, db *mongo.Database) error {
+	posts := db.Collection("posts")
+	missing := bson.M{"author_id": bson.M{"$exists": false}}
+	authors, err := posts.Distinct(ctx, "author", missing)
+	if err != nil {
+		return err
+	}
+	for _, author := range authors {
+		var user struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		err := db.Collection("users").FindOne(ctx, bson.M{"username": author}).Decode(&user)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		filter := bson.M{"author": author, "author_id": bson.M{"$exists": false}}
+		if _, err := posts.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"author_id": user.ID}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectionIndexes are indexes of one collection
+type collectionIndexes struct {
+	collection string
+	indexes    []mongo.IndexModel
+}
+
+// indexMigration creates the indexes on the way up and drops them on the way down.
+// Creating an index that exists with the same keys and options does nothing.
+func indexMigration(version int, description string, sets ...collectionIndexes) Migration {
+	return Migration{
+		Version:     version,
+		Description: description,
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			for _, set := range sets {
+				if _, err := db.Collection(set.collection).Indexes().CreateMany(ctx, set.indexes); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			for _, set := range sets {
+				for _, index := range set.indexes {
+					if err := dropIndex(ctx, db, set.collection, index.Keys); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// Server error codes for a missing index or collection
+const (
+	codeNamespaceNotFound = 26
+	codeIndexNotFound     = 27
+)
+
+// dropIndex drops the index of collection with keys. Indexes are matched by keys
+// rather than name, as names generated by older builds may differ.
+func dropIndex(ctx context.Context, db *mongo.Database, collection string, keys interface{}) error {
+	err := db.RunCommand(ctx, bson.D{{Key: "dropIndexes", Value: collection}, {Key: "index", Value: keys}}).Err()
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && (commandErr.Code == codeNamespaceNotFound || commandErr.Code == codeIndexNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+// Package migrations evolves the Mongo schema one numbered step at a time and
+// records the steps applied in the "migrations" collection.
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migration moves the schema to Version with Up and back with Down. Both must
+// be safe to run again: instances starting together may race to apply a step.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+// Status is a migration and when it was applied, nil when it is pending
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies Migrations to DB and records them in Records
+type Migrator struct {
+	DB         *mongo.Database
+	Records    repositories.MigrationRepository
+	Migrations []Migration // Ascending versions
+}
+
+// New returns a Migrator for All the migrations of this build
+func New(db *mongo.Database, records repositories.MigrationRepository) *Migrator {
+	return &Migrator{DB: db, Records: records, Migrations: All}
+}
+
+// Status lists every migration of this build, applied or not
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(m.Migrations))
+	for i, migration := range m.Migrations {
+		statuses[i] = Status{Migration: migration}
+		if record, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &record.AppliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied, in order
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range m.Migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the pending migrations up to version target, or all of them when
+// target is 0, and returns those it applied
+func (m *Migrator) Up(ctx context.Context, target int) ([]Migration, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, migration := range pending {
+		if target > 0 && migration.Version > target {
+			break
+		}
+		if err := migration.Up(ctx, m.DB); err != nil {
+			return done, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		err := m.Records.Record(ctx, models.MigrationRecord{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now(),
+		})
+		if err != nil {
+			return done, err
+		}
+		slog.InfoContext(ctx, "Applied migration", "version", migration.Version, "description", migration.Description)
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// those it reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	records, err := m.Records.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]Migration, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		byVersion[migration.Version] = migration
+	}
+	var done []Migration
+	for i := len(records) - 1; i >= 0 && len(done) < steps; i-- {
+		migration, ok := byVersion[records[i].Version]
+		if !ok {
+			return done, fmt.Errorf("migration %d was applied by a newer build and cannot be reverted by this one", records[i].Version)
+		}
+		if err := migration.Down(ctx, m.DB); err != nil {
+			return done, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		if err := m.Records.Remove(ctx, migration.Version); err != nil {
+			return done, err
+		}
+		slog.InfoContext(ctx, "Reverted migration", "version", migration.Version, "description", migration.Description)
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]models.MigrationRecord, error) {
+	records, err := m.Records.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int]models.MigrationRecord, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// validate checks that versions ascend and every migration can go both ways
+func (m *Migrator) validate() error {
+	for i, migration := range m.Migrations {
+		if migration.Up == nil || migration.Down == nil {
+			return fmt.Errorf("migration %d needs both Up and Down", migration.Version)
+		}
+		if i > 0 && migration.Version <= m.Migrations[i-1].Version {
+			return fmt.Errorf("migration %d must come after %d", migration.Version, m.Migrations[i-1].Version)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// newTestMigrator returns a Migrator over versions that only note in schema
+// which of them are applied
+func newTestMigrator(schema map[int]bool, versions ...int) *Migrator {
+	migrations := make([]Migration, len(versions))
+	for i, version := range versions {
+		migrations[i] = Migration{
+			Version:     version,
+			Description: "test",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				schema[version] = true
+				return nil
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				delete(schema, version)
+				return nil
+			},
+		}
+	}
+	return &Migrator{Records: repositories.NewMemoryMigrationRepository(), Migrations: migrations}
+}
+
+func versions(migrations []Migration) []int {
+	versions := []int{}
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.TODO()
+	schema := map[int]bool{}
+	migrator := newTestMigrator(schema, 1, 2, 3)
+
+	done, err := migrator.Up(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions(done))
+
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, versions(pending))
+
+	done, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, versions(done))
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true}, schema)
+
+	// Nothing is left to apply
+	done, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+
+	done, err = migrator.Down(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 2}, versions(done))
+	assert.Equal(t, map[int]bool{1: true}, schema)
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+	assert.Nil(t, statuses[2].AppliedAt)
+}
+
+func TestUpStopsAtFailure(t *testing.T) {
+	ctx := context.TODO()
+	schema := map[int]bool{}
+	migrator := newTestMigrator(schema, 1, 2, 3)
+	migrator.Migrations[1].Up = func(ctx context.Context, db *mongo.Database) error {
+		return errors.New("duplicate usernames")
+	}
+
+	done, err := migrator.Up(ctx, 0)
+	assert.ErrorContains(t, err, "migration 2")
+	assert.Equal(t, []int{1}, versions(done))
+
+	// The failed migration is still pending
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, versions(pending))
+}
+
+func TestDownUnknownVersion(t *testing.T) {
+	ctx := context.TODO()
+	migrator := newTestMigrator(map[int]bool{}, 1)
+	migrator.Records.Record(ctx, models.MigrationRecord{Version: 1})
+	migrator.Records.Record(ctx, models.MigrationRecord{Version: 2})
+
+	done, err := migrator.Down(ctx, 1)
+	assert.Error(t, err)
+	assert.Empty(t, done)
+}
+
+func TestAllValid(t *testing.T) {
+	assert.NoError(t, New(nil, repositories.NewMemoryMigrationRepository()).validate())
+}
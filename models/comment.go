@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Comment model info
+// @Description Comment on a post. Replies point at their parent comment.
+type Comment struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty" swaggertype:"primitive,string"`
+	PostID     primitive.ObjectID  `bson:"post_id" json:"post_id" swaggertype:"primitive,string"`
+	ParentID   *primitive.ObjectID `bson:"parent_id" json:"parent_id,omitempty" swaggertype:"primitive,string"` // Empty for top level comments
+	AuthorID   primitive.ObjectID  `bson:"author_id" json:"author_id" swaggertype:"primitive,string"`
+	Author     string              `bson:"author" json:"author"`
+	Content    string              `bson:"content" json:"content"`
+	ReplyCount int64               `bson:"reply_count" json:"reply_count"`
+	Deleted    bool                `bson:"deleted,omitempty" json:"deleted,omitempty"` // Tombstone kept so replies stay in their thread
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	EditedAt   *time.Time          `bson:"edited_at,omitempty" json:"edited_at,omitempty"`
+}
+
+// CommentInput model info
+// @Description Comment data sent by the client
+type CommentInput struct {
+	Content  string              `json:"content" binding:"required,notblank,max=2000" maxLength:"2000"`
+	ParentID *primitive.ObjectID `json:"parent_id,omitempty" swaggertype:"primitive,string"` // Comment to reply to
+}
+
+// CommentPage model info
+// @Description A page of comments on one thread level, newest first
+type CommentPage struct {
+	Data       []Comment  `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Follow model info
+// @Description Follow relationship: Follower follows Followee
+type Follow struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty" swaggertype:"primitive,string"`
+	FollowerID primitive.ObjectID `bson:"follower_id" json:"follower_id" swaggertype:"primitive,string"`
+	Follower   string             `bson:"follower" json:"follower"` // Username of the follower
+	FolloweeID primitive.ObjectID `bson:"followee_id" json:"followee_id" swaggertype:"primitive,string"`
+	Followee   string             `bson:"followee" json:"followee"` // Username of the followed user
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// FollowPage model info
+// @Description A page of follow relationships, most recent first
+type FollowPage struct {
+	Data       []Follow   `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Profile model info
+// @Description Public profile of a user
+type Profile struct {
+	ID             primitive.ObjectID `json:"id" swaggertype:"primitive,string"`
+	Username       string             `json:"username"`
+	FollowersCount int64              `json:"followers_count"`
+	FollowingCount int64              `json:"following_count"`
+	Following      bool               `json:"following"` // Whether the caller follows this user
+}
@@ -0,0 +1,22 @@
+package models
+
+// Health statuses
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Readiness model info
+// @Description Whether the service can take traffic, with the state of each dependency
+type Readiness struct {
+	Status       string                      `json:"status" example:"up"` // up when every dependency is up
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// DependencyStatus model info
+// @Description Result of checking one dependency
+type DependencyStatus struct {
+	Status    string  `json:"status" example:"up"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
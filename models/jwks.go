@@ -0,0 +1,20 @@
+package models
+
+// JWK model info
+// @Description Public JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`           // RSA or OKP
+	Kid string `json:"kid"`           // Key id matching the "kid" token header
+	Use string `json:"use"`           // Always "sig"
+	Alg string `json:"alg"`           // RS256 or EdDSA
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // Ed25519
+	X   string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+// JWKS model info
+// @Description JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// MigrationRecord notes that the schema migration Version has been applied
+type MigrationRecord struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
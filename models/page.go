@@ -0,0 +1,17 @@
+package models
+
+// Pagination model info
+// @Description Cursor pagination information
+type Pagination struct {
+	Limit      int    `json:"limit"`                 // Page size that was applied
+	NextCursor string `json:"next_cursor,omitempty"` // Pass as cursor to get the next (older) page
+	PrevCursor string `json:"prev_cursor,omitempty"` // Pass as cursor to get the previous (newer) page
+	HasMore    bool   `json:"has_more"`              // Whether more items exist in the requested direction
+}
+
+// PostPage model info
+// @Description A page of posts
+type PostPage struct {
+	Data       []Post     `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
@@ -1,14 +1,74 @@
 package models
 
 import (
+	"encoding/json"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Times posts can be sorted by, newest first, as named in the sort query parameter
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortEditedAt  = "edited_at"
+)
+
+// SortDeletedAt orders the trash, most recently deleted first
+const SortDeletedAt = "deleted_at"
+
+var PostSorts = []string{SortCreatedAt, SortUpdatedAt, SortEditedAt}
+
 // Post model info
 // @Description Post information
 type Post struct {
-	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty" swaggertype:"primitive,string"`
-	Title   string             `bson:"title" json:"title"`
-	Content string             `bson:"content" json:"content"`
-	Author  string             `bson:"author" json:"author"`
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty" swaggertype:"primitive,string"`
+	Title          string             `bson:"title" json:"title" binding:"required,notblank,max=200" maxLength:"200"`
+	Content        string             `bson:"content" json:"content" binding:"required,notblank,max=10000" maxLength:"10000"`
+	AuthorID       primitive.ObjectID `bson:"author_id,omitempty" json:"author_id,omitempty" swaggertype:"primitive,string"` // Set by the server from the authenticated user
+	Author         string             `bson:"author" json:"author"`                                                          // Username of the author, set by the server
+	CommentCount   int64              `bson:"comment_count,omitempty" json:"comment_count"`                                  // Maintained by the server
+	Reactions      map[string]int64   `bson:"reactions,omitempty" json:"reactions,omitempty"`                                // Reaction counts by type, maintained by the server
+	ViewerReacted  bool               `bson:"-" json:"viewer_reacted"`                                                       // Whether the authenticated caller reacted
+	ViewerReaction string             `bson:"-" json:"viewer_reaction,omitempty"`                                            // The caller's reaction type, if any
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`                                                  // Set by the server
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`                                                  // Set by the server on every change
+	EditedAt       *time.Time         `bson:"edited_at,omitempty" json:"edited_at,omitempty"`                                // Set by the server when the title or content changes
+	Edited         bool               `bson:"-" json:"edited"`                                                               // Whether EditedAt is set
+	DeletedAt      *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`                              // Set by the server when the post is moved to the trash
+	DeletedBy      string             `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`                              // Username of whoever moved the post to the trash
+	Version        int64              `bson:"version" json:"version"`                                                        // Set by the server and bumped by every change of the post itself; a write may send it instead of If-Match
+}
+
+// SortTime returns the time of p named by sort, one of PostSorts or SortDeletedAt,
+// and whether it is set
+func (p Post) SortTime(sort string) (time.Time, bool) {
+	switch sort {
+	case SortCreatedAt:
+		return p.CreatedAt, true
+	case SortUpdatedAt:
+		return p.UpdatedAt, true
+	case SortEditedAt:
+		if p.EditedAt != nil {
+			return *p.EditedAt, true
+		}
+	case SortDeletedAt:
+		if p.DeletedAt != nil {
+			return *p.DeletedAt, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// MarshalJSON fills in Edited from EditedAt
+func (p Post) MarshalJSON() ([]byte, error) {
+	type post Post
+	p.Edited = p.EditedAt != nil
+	return json.Marshal(post(p))
+}
+
+// PostVersion is the optional body of a post delete, which may name the
+// version it expects instead of sending If-Match
+type PostVersion struct {
+	Version int64 `json:"version" example:"3"`
 }
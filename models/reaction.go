@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultReactionTypes are offered when the configuration names none
+var DefaultReactionTypes = []string{"like", "love", "laugh", "wow", "sad", "angry"}
+
+// Reaction model info
+// @Description A user's reaction to a post. Each user has at most one per post.
+type Reaction struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty" swaggertype:"primitive,string"`
+	PostID    primitive.ObjectID `bson:"post_id" json:"post_id" swaggertype:"primitive,string"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id" swaggertype:"primitive,string"`
+	Type      string             `bson:"type" json:"type"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ReactionInput model info
+// @Description Reaction sent by the client
+type ReactionInput struct {
+	Type string `json:"type" binding:"required" example:"like"` // One of the configured reaction types
+}
@@ -3,6 +3,27 @@ package models
 // Response model info
 // @Description Response information
 type Response struct {
-	Message string `json:"message,omitempty"` // Response message
-	Error   string `json:"error,omitempty"`
+	Message string       `json:"message,omitempty"` // Response message
+	Error   string       `json:"error,omitempty"`
+	Code    string       `json:"code,omitempty"`   // Machine-readable error code, see package apperrors
+	Fields  []FieldError `json:"fields,omitempty"` // Fields that failed validation
+}
+
+// Validation error codes reported in FieldError.Code
+const (
+	CodeRequired          = "required"
+	CodeTooShort          = "too_short"
+	CodeTooLong           = "too_long"
+	CodeInvalidCharacters = "invalid_characters"
+	CodeInvalidChoice     = "invalid_choice"
+	CodeInvalid           = "invalid"
+	CodeReadOnly          = "read_only"
+)
+
+// FieldError model info
+// @Description A request field that failed validation
+type FieldError struct {
+	Field   string `json:"field" example:"username"` // JSON name of the field
+	Code    string `json:"code" example:"too_short"` // Machine-readable reason: required, too_short, too_long, invalid_characters, invalid_choice, invalid or read_only
+	Message string `json:"message" example:"must be at least 3 characters"`
 }
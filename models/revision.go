@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Post fields that revisions track
+const (
+	FieldTitle   = "title"
+	FieldContent = "content"
+)
+
+// Revision model info
+// @Description An immutable record of a post as one edit left it. Revision 1 is the post as it was written.
+type Revision struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"primitive,string"`
+	PostID       primitive.ObjectID `bson:"post_id" json:"post_id" swaggertype:"primitive,string"`
+	Number       int                `bson:"number" json:"number"` // Counts up from 1 within the post
+	EditorID     primitive.ObjectID `bson:"editor_id" json:"editor_id" swaggertype:"primitive,string"`
+	Editor       string             `bson:"editor" json:"editor"` // Username of the user who made the edit
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	Title        string             `bson:"title" json:"title"`
+	Content      string             `bson:"content" json:"content"`
+	Changes      []FieldChange      `bson:"changes" json:"changes"`                                 // Fields that differ from the previous revision
+	RestoredFrom int                `bson:"restored_from,omitempty" json:"restored_from,omitempty"` // Number of the revision this one restored
+}
+
+// FieldChange model info
+// @Description One field changed by an edit
+type FieldChange struct {
+	Field string `bson:"field" json:"field" enums:"title,content"`
+	From  string `bson:"from" json:"from"`
+	To    string `bson:"to" json:"to"`
+}
+
+// RevisionPage model info
+// @Description A page of revisions of a post, newest first
+type RevisionPage struct {
+	Data       []Revision `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
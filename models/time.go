@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// Now is the current time in UTC to the millisecond, as Mongo stores it, so
+// timestamps read back equal those handed out
+func Now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimelineEntry places a post on the home timeline of a user when timelines are fanned out on write
+type TimelineEntry struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	UserID   primitive.ObjectID `bson:"user_id"`
+	PostID   primitive.ObjectID `bson:"post_id"`
+	AuthorID primitive.ObjectID `bson:"author_id"`
+}
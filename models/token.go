@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken is the server side record of a refresh token. Only the hash of
+// the token is stored; every rotation of a login shares the same FamilyID.
+type RefreshToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Hash      string             `bson:"hash"`
+	FamilyID  string             `bson:"family_id"`
+	Username  string             `bson:"username"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty"`
+	RevokedAt *time.Time         `bson:"revoked_at,omitempty"`
+}
+
+// RefreshInput model info
+// @Description RefreshInput information
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -1,26 +1,75 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Roles a user can hold. Moderators may suspend users and delete any post;
+// admins may also list users and change roles.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 // User model info
 // @Description User information
 type User struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty" swaggertype:"primitive,string"`
-	Username string             `bson:"username" json:"username"`
-	Password string             `bson:"password" json:"password"`
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty" swaggertype:"primitive,string"`
+	Username  string             `bson:"username" json:"username" binding:"required,min=3,max=32,username" minLength:"3" maxLength:"32" example:"johndoe"` // Letters, digits and underscores
+	Password  string             `bson:"password" json:"password" binding:"required,min=8,max=72" minLength:"8" maxLength:"72"`
+	Role      string             `bson:"role,omitempty" json:"role,omitempty"`           // Set by the server, ignored on register
+	Suspended bool               `bson:"suspended,omitempty" json:"suspended,omitempty"` // Suspended users cannot log in or use their tokens
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`                   // Set by the server
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`                   // Set by the server when the role or suspension changes
+}
+
+// EffectiveRole is the role of u. Accounts created before roles were stored
+// have none and are users.
+func (u User) EffectiveRole() string {
+	if u.Role == "" {
+		return RoleUser
+	}
+	return u.Role
+}
+
+// UserSummary model info
+// @Description A user as listed to admins
+type UserSummary struct {
+	ID        primitive.ObjectID `json:"id" swaggertype:"primitive,string"`
+	Username  string             `json:"username"`
+	Role      string             `json:"role" enums:"user,moderator,admin"`
+	Suspended bool               `json:"suspended"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// UserPage model info
+// @Description A page of users, newest first
+type UserPage struct {
+	Data       []UserSummary `json:"data"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+// RoleInput model info
+// @Description The role to give a user
+type RoleInput struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin" enums:"user,moderator,admin" example:"moderator"`
 }
 
 // LoginInput model info
 // @Description LoginInput information
 type LoginInput struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" binding:"required" example:"johndoe"`
+	Password string `json:"password" binding:"required"`
 }
 
 // AuthResponse model info
 // @Description AuthResponse information
 type AuthResponse struct {
-	Token string `json:"token"` // Response message
+	Token        string `json:"token"`         // Access token for the Authorization header
+	ExpiresIn    int64  `json:"expires_in"`    // Seconds until the access token expires
+	RefreshToken string `json:"refresh_token"` // Single-use token for POST /token/refresh
 }
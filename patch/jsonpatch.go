@@ -0,0 +1,275 @@
+package patch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// operation is one step of a JSON Patch. Value stays nil when the member is
+// missing, which tells it apart from a null value.
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Apply runs the operations of the JSON Patch on doc in order. Either all of
+// them apply or doc is left as it was.
+func Apply(doc []byte, patch []byte) ([]byte, error) {
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	var operations []operation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, invalid("not a JSON array of operations")
+	}
+	for _, op := range operations {
+		var err error
+		if target, err = op.apply(target); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(target)
+}
+
+func (op operation) apply(doc any) (any, error) {
+	if op.Path == nil {
+		return nil, invalid("%s operation without a path", op.Op)
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, invalid("%s operation on %s without a value", op.Op, *op.Path)
+		}
+		json.Unmarshal(op.Value, &value)
+	case "move", "copy":
+		if op.From == nil {
+			return nil, invalid("%s operation to %s without a from", op.Op, *op.Path)
+		}
+	case "remove":
+	default:
+		return nil, invalid("unknown operation %q", op.Op)
+	}
+
+	switch op.Op {
+	case "add":
+		return add(doc, path, value)
+	case "remove":
+		doc, _, err = remove(doc, path)
+		return doc, err
+	case "replace":
+		if _, err := get(doc, path); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return value, nil
+		}
+		if doc, _, err = remove(doc, path); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case "test":
+		found, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(found, value) {
+			return nil, conflict("test of %s failed", *op.Path)
+		}
+		return doc, nil
+	}
+
+	from, err := parsePointer(*op.From)
+	if err != nil {
+		return nil, err
+	}
+	if op.Op == "move" {
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, invalid("cannot move %s into itself", *op.From)
+		}
+		if doc, value, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	}
+	found, err := get(doc, from)
+	if err != nil {
+		return nil, err
+	}
+	return add(doc, path, clone(found))
+}
+
+// pointer is a JSON Pointer (RFC 6901) split into its unescaped reference tokens
+type pointer []string
+
+func parsePointer(raw string) (pointer, error) {
+	if raw == "" {
+		return pointer{}, nil
+	}
+	if !strings.HasPrefix(raw, "/") {
+		return nil, invalid("path %q does not start with /", raw)
+	}
+	tokens := strings.Split(raw[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func (p pointer) String() string {
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteString("/")
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// index reads token as a position in an array of length n; end allows n itself
+func index(token string, n int, end bool, path pointer) (int, error) {
+	if end && token == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, conflict("path %s has an invalid array index", path)
+	}
+	if i > n || (i == n && !end) {
+		return 0, conflict("path %s is out of range", path)
+	}
+	return i, nil
+}
+
+func get(doc any, path pointer) (any, error) {
+	for depth, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, conflict("path %s does not exist", path[:depth+1])
+			}
+			doc = value
+		case []any:
+			i, err := index(token, len(node), false, path[:depth+1])
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, conflict("path %s does not exist", path[:depth+1])
+		}
+	}
+	return doc, nil
+}
+
+// add returns doc with value added at path. Arrays grow by the new element
+// and object members are set whether they exist or not.
+func add(doc any, path pointer, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[token] = value
+		return doc, nil
+	case []any:
+		i, err := index(token, len(node), true, path)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node[:i], append([]any{value}, node[i:]...)...)
+		return set(doc, path[:len(path)-1], node)
+	default:
+		return nil, conflict("path %s does not exist", path[:len(path)-1])
+	}
+}
+
+// remove returns doc without the value at path, and that value
+func remove(doc any, path pointer) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, conflict("cannot remove the whole document")
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		value, ok := node[token]
+		if !ok {
+			return nil, nil, conflict("path %s does not exist", path)
+		}
+		delete(node, token)
+		return doc, value, nil
+	case []any:
+		i, err := index(token, len(node), false, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		doc, err = set(doc, path[:len(path)-1], node)
+		return doc, value, err
+	default:
+		return nil, nil, conflict("path %s does not exist", path)
+	}
+}
+
+// set returns doc with the existing value at path replaced by value, which
+// arrays need since growing or shrinking them makes a new slice
+func set(doc any, path pointer, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[token] = value
+	case []any:
+		i, err := index(token, len(node), false, path)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = value
+	}
+	return doc, nil
+}
+
+// clone deep copies a decoded JSON value so copies do not share later changes
+func clone(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		object := make(map[string]any, len(v))
+		for name, member := range v {
+			object[name] = clone(member)
+		}
+		return object
+	case []any:
+		array := make([]any, len(v))
+		for i, element := range v {
+			array[i] = clone(element)
+		}
+		return array
+	default:
+		return v
+	}
+}
@@ -0,0 +1,77 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902)
+// documents to JSON documents.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Media types of the patch documents, as sent in Content-Type
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalid is wrapped by the errors of patch documents that are malformed
+	ErrInvalid = errors.New("invalid patch")
+	// ErrConflict is wrapped by the errors of patches that do not apply to the
+	// document, such as a missing path or a failed test operation
+	ErrConflict = errors.New("patch does not apply")
+)
+
+// Error says why a patch was rejected. Err is ErrInvalid or ErrConflict.
+type Error struct {
+	Err    error
+	Detail string // Meant for clients, such as "path /title does not exist"
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error() + ": " + e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func invalid(format string, args ...any) error {
+	return &Error{Err: ErrInvalid, Detail: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...any) error {
+	return &Error{Err: ErrConflict, Detail: fmt.Sprintf(format, args...)}
+}
+
+// Merge applies the merge patch to doc. Members of the patch set the members
+// of doc, objects merge recursively and null removes a member.
+func Merge(doc []byte, patch []byte) ([]byte, error) {
+	var target, changes any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, invalid("not valid JSON")
+	}
+	return json.Marshal(merge(target, changes))
+}
+
+func merge(target any, changes any) any {
+	members, ok := changes.(map[string]any)
+	if !ok {
+		return changes
+	}
+	object, ok := target.(map[string]any)
+	if !ok {
+		object = map[string]any{}
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(object, name)
+		} else {
+			object[name] = merge(object[name], value)
+		}
+	}
+	return object
+}
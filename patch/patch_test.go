@@ -0,0 +1,102 @@
+package patch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"set member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"replace array", `{"a":["b"]}`, `{"a":["c"]}`, `{"a":["c"]}`},
+		{"merge nested", `{"a":{"b":"c","d":"e"}}`, `{"a":{"b":"f","d":null}}`, `{"a":{"b":"f"}}`},
+		{"object over value", `{"a":"b"}`, `{"a":{"c":"d"}}`, `{"a":{"c":"d"}}`},
+		{"replace document", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"empty patch", `{"a":"b"}`, `{}`, `{"a":"b"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Merge([]byte(tt.doc), []byte(tt.patch))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	_, err := Merge([]byte(`{}`), []byte(`{`))
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add member", `{"a":"b"}`, `[{"op":"add","path":"/c","value":"d"}]`, `{"a":"b","c":"d"}`},
+		{"add to array", `{"a":["b","d"]}`, `[{"op":"add","path":"/a/1","value":"c"}]`, `{"a":["b","c","d"]}`},
+		{"append to array", `{"a":["b"]}`, `[{"op":"add","path":"/a/-","value":"c"}]`, `{"a":["b","c"]}`},
+		{"add null", `{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`},
+		{"remove member", `{"a":"b","c":"d"}`, `[{"op":"remove","path":"/a"}]`, `{"c":"d"}`},
+		{"remove from array", `{"a":["b","c","d"]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":["b","d"]}`},
+		{"replace member", `{"a":"b"}`, `[{"op":"replace","path":"/a","value":"c"}]`, `{"a":"c"}`},
+		{"replace in array", `{"a":["b","c"]}`, `[{"op":"replace","path":"/a/1","value":"d"}]`, `{"a":["b","d"]}`},
+		{"move member", `{"a":{"b":"c"}}`, `[{"op":"move","from":"/a/b","path":"/d"}]`, `{"a":{},"d":"c"}`},
+		{"copy member", `{"a":{"b":"c"}}`, `[{"op":"copy","from":"/a","path":"/d"}]`, `{"a":{"b":"c"},"d":{"b":"c"}}`},
+		{"test then replace", `{"a":"b"}`, `[{"op":"test","path":"/a","value":"b"},{"op":"replace","path":"/a","value":"c"}]`, `{"a":"c"}`},
+		{"escaped path", `{"a/b":"c","d~e":"f"}`, `[{"op":"replace","path":"/a~1b","value":1},{"op":"remove","path":"/d~0e"}]`, `{"a/b":1}`},
+		{"replace document", `{"a":"b"}`, `[{"op":"replace","path":"","value":{"c":"d"}}]`, `{"c":"d"}`},
+		{"no operations", `{"a":"b"}`, `[]`, `{"a":"b"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  error
+	}{
+		{"not an array", `{"op":"add","path":"/a","value":1}`, ErrInvalid},
+		{"unknown operation", `[{"op":"merge","path":"/a"}]`, ErrInvalid},
+		{"missing path", `[{"op":"remove"}]`, ErrInvalid},
+		{"missing value", `[{"op":"add","path":"/a"}]`, ErrInvalid},
+		{"missing from", `[{"op":"copy","path":"/a"}]`, ErrInvalid},
+		{"relative path", `[{"op":"remove","path":"a"}]`, ErrInvalid},
+		{"move into itself", `[{"op":"move","from":"/b","path":"/b/c"}]`, ErrInvalid},
+		{"remove missing", `[{"op":"remove","path":"/c"}]`, ErrConflict},
+		{"replace missing", `[{"op":"replace","path":"/c","value":1}]`, ErrConflict},
+		{"add under missing", `[{"op":"add","path":"/c/d","value":1}]`, ErrConflict},
+		{"index out of range", `[{"op":"add","path":"/b/c/5","value":1}]`, ErrConflict},
+		{"leading zero index", `[{"op":"remove","path":"/b/c/01"}]`, ErrConflict},
+		{"failed test", `[{"op":"test","path":"/a","value":"z"}]`, ErrConflict},
+		{"remove document", `[{"op":"remove","path":""}]`, ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Apply([]byte(`{"a":"b","b":{"c":["d","e"]}}`), []byte(tt.patch))
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	// Operations after a failing one never leave a partial result
+	_, err := Apply([]byte(`{"a":"b"}`), []byte(`[{"op":"replace","path":"/a","value":"c"},{"op":"test","path":"/a","value":"b"}]`))
+	assert.ErrorIs(t, err, ErrConflict)
+	var patchErr *Error
+	assert.ErrorAs(t, err, &patchErr)
+	assert.Equal(t, "test of /a failed", patchErr.Detail)
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Lockout locks an account out for Base once it reaches Threshold consecutive
+// failed logins, doubling the lock with every further failure up to Max (no
+// doubling when Max is zero).
+// Failures are forgotten after a successful login, or Window after the last
+// failure or lock.
+type Lockout struct {
+	Store     Store
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+	Window    time.Duration
+	Now       func() time.Time
+}
+
+func NewLockout(store Store, threshold int, base time.Duration, max time.Duration, window time.Duration) *Lockout {
+	return &Lockout{Store: store, Threshold: threshold, Base: base, Max: max, Window: window, Now: time.Now}
+}
+
+// Enabled reports whether l ever locks accounts
+func (l *Lockout) Enabled() bool {
+	return l != nil && l.Threshold > 0 && l.Base > 0
+}
+
+// Locked returns how long key stays locked out, zero when it is not
+func (l *Lockout) Locked(ctx context.Context, key string) (time.Duration, error) {
+	if !l.Enabled() {
+		return 0, nil
+	}
+	attempts, err := l.Store.Attempts(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if remaining := attempts.LockedUntil.Sub(l.Now()); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+// Fail records a failed login of key and returns how long key is now locked
+// out, zero when it is not. The lock is worked out from the count the store
+// returns, so concurrent failures each count.
+func (l *Lockout) Fail(ctx context.Context, key string) (time.Duration, error) {
+	if !l.Enabled() {
+		return 0, nil
+	}
+	failures, err := l.Store.RecordFailure(ctx, key, l.Window)
+	if err != nil {
+		return 0, err
+	}
+	excess := failures - l.Threshold
+	if excess < 0 {
+		return 0, nil
+	}
+	lock := l.Base
+	for i := 0; i < excess && lock < l.Max; i++ {
+		lock *= 2
+	}
+	if l.Max > 0 && lock > l.Max {
+		lock = l.Max
+	}
+	if err := l.Store.LockUntil(ctx, key, l.Now().Add(lock), l.Window); err != nil {
+		return 0, err
+	}
+	return lock, nil
+}
+
+// Succeed forgets the failed logins of key
+func (l *Lockout) Succeed(ctx context.Context, key string) error {
+	if !l.Enabled() {
+		return nil
+	}
+	return l.Store.ResetAttempts(ctx, key)
+}
@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockout(t *testing.T) {
+	store, clock := newTestStore()
+	lockout := NewLockout(store, 3, time.Minute, 4*time.Minute, 15*time.Minute)
+	lockout.Now = clock.Now
+	ctx := context.Background()
+
+	// Failures below the threshold do not lock
+	for i := 0; i < 2; i++ {
+		lock, err := lockout.Fail(ctx, "login:alice")
+		assert.NoError(t, err)
+		assert.Zero(t, lock)
+	}
+	locked, _ := lockout.Locked(ctx, "login:alice")
+	assert.Zero(t, locked)
+
+	// Then every failure doubles the lock, up to the maximum
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		lock, err := lockout.Fail(ctx, "login:alice")
+		assert.NoError(t, err)
+		assert.Equal(t, want, lock)
+	}
+	locked, _ = lockout.Locked(ctx, "login:alice")
+	assert.Equal(t, 4*time.Minute, locked)
+
+	clock.Advance(4 * time.Minute)
+	locked, _ = lockout.Locked(ctx, "login:alice")
+	assert.Zero(t, locked)
+
+	// A successful login starts over
+	assert.NoError(t, lockout.Succeed(ctx, "login:alice"))
+	lock, _ := lockout.Fail(ctx, "login:alice")
+	assert.Zero(t, lock)
+}
+
+func TestLockoutWindow(t *testing.T) {
+	store, clock := newTestStore()
+	lockout := NewLockout(store, 2, time.Minute, time.Hour, 15*time.Minute)
+	lockout.Now = clock.Now
+	ctx := context.Background()
+
+	lockout.Fail(ctx, "login:alice")
+	// Failures far apart are forgotten
+	clock.Advance(16 * time.Minute)
+	lock, _ := lockout.Fail(ctx, "login:alice")
+	assert.Zero(t, lock)
+}
+
+// lockstepStore holds the first n reads and failures it answers until all n
+// have been answered, so that nothing is written in between
+type lockstepStore struct {
+	*MemoryStore
+	n       int32
+	calls   atomic.Int32
+	arrived sync.WaitGroup
+}
+
+func newLockstepStore(store *MemoryStore, n int) *lockstepStore {
+	s := &lockstepStore{MemoryStore: store, n: int32(n)}
+	s.arrived.Add(n)
+	return s
+}
+
+func (s *lockstepStore) wait() {
+	if s.calls.Add(1) <= s.n {
+		s.arrived.Done()
+		s.arrived.Wait()
+	}
+}
+
+func (s *lockstepStore) Attempts(ctx context.Context, key string) (Attempts, error) {
+	attempts, err := s.MemoryStore.Attempts(ctx, key)
+	s.wait()
+	return attempts, err
+}
+
+func (s *lockstepStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	failures, err := s.MemoryStore.RecordFailure(ctx, key, window)
+	s.wait()
+	return failures, err
+}
+
+func TestLockoutConcurrent(t *testing.T) {
+	store, clock := newTestStore()
+	lockout := NewLockout(newLockstepStore(store, 100), 3, time.Minute, time.Hour, 15*time.Minute)
+	lockout.Now = clock.Now
+	ctx := context.Background()
+
+	// Failures at the same time are all counted
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lockout.Fail(ctx, "login:alice")
+		}()
+	}
+	wg.Wait()
+
+	attempts, _ := store.Attempts(ctx, "login:alice")
+	assert.Equal(t, 100, attempts.Failures)
+	locked, _ := lockout.Locked(ctx, "login:alice")
+	assert.Equal(t, time.Hour, locked)
+}
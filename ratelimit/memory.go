@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the memory store drops idle entries
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // When the bucket will have refilled, after which it can be dropped
+}
+
+type attemptsEntry struct {
+	attempts Attempts
+	expires  time.Time
+}
+
+// MemoryStore keeps the state in process memory, so every instance limits on its own
+type MemoryStore struct {
+	Now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	attempts  map[string]attemptsEntry
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		Now:      time.Now,
+		buckets:  map[string]*bucket{},
+		attempts: map[string]attemptsEntry{},
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	s.sweep(now)
+
+	capacity := float64(limit.Requests)
+	rate := limit.rate()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	result := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+func (s *MemoryStore) Attempts(_ context.Context, key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.attempts[key]
+	if !ok || !s.Now().Before(entry.expires) {
+		return Attempts{}, nil
+	}
+	return entry.attempts, nil
+}
+
+func (s *MemoryStore) RecordFailure(_ context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	entry, ok := s.attempts[key]
+	if !ok || !now.Before(entry.expires) {
+		entry = attemptsEntry{}
+	}
+	entry.attempts.Failures++
+	entry.expires = later(entry.expires, now.Add(window))
+	s.attempts[key] = entry
+	return entry.attempts.Failures, nil
+}
+
+func (s *MemoryStore) LockUntil(_ context.Context, key string, until time.Time, window time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.attempts[key]
+	if !ok || !s.Now().Before(entry.expires) {
+		entry = attemptsEntry{}
+	}
+	entry.attempts.LockedUntil = later(entry.attempts.LockedUntil, until)
+	entry.expires = later(entry.expires, entry.attempts.LockedUntil.Add(window))
+	s.attempts[key] = entry
+	return nil
+}
+
+func (s *MemoryStore) ResetAttempts(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+// sweep drops the full buckets and expired attempts, at most every sweepInterval
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, entry := range s.attempts {
+		if !now.Before(entry.expires) {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+func later(a time.Time, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
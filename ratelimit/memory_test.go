@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a clock tests move by hand
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.Now = clock.Now
+	return store, clock
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	store, clock := newTestStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 3, Per: 3 * time.Second}
+
+	// A full bucket lets a burst through
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "ip:1.2.3.4", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+		assert.Equal(t, 3, result.Limit)
+	}
+
+	result, _ := store.Take(ctx, "ip:1.2.3.4", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// Other keys have buckets of their own
+	result, _ = store.Take(ctx, "ip:5.6.7.8", limit)
+	assert.True(t, result.Allowed)
+
+	// One token comes back per second
+	clock.Advance(time.Second)
+	result, _ = store.Take(ctx, "ip:1.2.3.4", limit)
+	assert.True(t, result.Allowed)
+	result, _ = store.Take(ctx, "ip:1.2.3.4", limit)
+	assert.False(t, result.Allowed)
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store, clock := newTestStore()
+	ctx := context.Background()
+	store.Take(ctx, "ip:1.2.3.4", Limit{Requests: 1, Per: time.Second})
+	store.RecordFailure(ctx, "login:alice", time.Minute)
+
+	clock.Advance(2 * time.Minute)
+	store.Take(ctx, "ip:5.6.7.8", Limit{Requests: 1, Per: time.Hour})
+
+	// Idle state is dropped, state in use is kept
+	assert.NotContains(t, store.buckets, "ip:1.2.3.4")
+	assert.Contains(t, store.buckets, "ip:5.6.7.8")
+	assert.Empty(t, store.attempts)
+}
@@ -0,0 +1,58 @@
+// Package ratelimit limits how often callers may hit the API with token
+// buckets, and locks accounts out after repeated failed logins. The state is
+// kept in a Store so that instances can share it.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit lets Requests through per Per, in bursts of up to Requests
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Enabled reports whether l limits anything
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// rate is the number of tokens added to the bucket per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the state of a bucket after a Take
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // Until the bucket is full again
+	RetryAfter time.Duration // Until the next request is allowed, when denied
+}
+
+// Attempts tracks the failed logins of an account
+type Attempts struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// Store keeps the buckets and login attempts
+type Store interface {
+	// Take removes a token from the bucket of key, created full for limit
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Attempts returns the failed logins of key, zero when there are none
+	Attempts(ctx context.Context, key string) (Attempts, error)
+	// RecordFailure adds a failed login to key and returns the failures it
+	// now has, in one step as $inc or INCR would, so concurrent failures all
+	// count. They are forgotten window after the last one, unless a lock
+	// keeps them longer.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	// LockUntil locks key out until until, unless it is already locked out
+	// for longer, and keeps its failures until window after the lock ends
+	LockUntil(ctx context.Context, key string, until time.Time, window time.Duration) error
+	// ResetAttempts forgets the failed logins of key
+	ResetAttempts(ctx context.Context, key string) error
+}
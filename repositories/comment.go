@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommentRepository stores comments and their reply threads
+type CommentRepository interface {
+	Create(ctx context.Context, comment models.Comment) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Comment, error)
+	// List returns up to page.Limit comments of postID replying to parentID, newest first.
+	// A nil parentID lists the top level comments.
+	List(ctx context.Context, postID primitive.ObjectID, parentID *primitive.ObjectID, page Page) ([]models.Comment, error)
+	UpdateContent(ctx context.Context, id primitive.ObjectID, content string) error
+	// Tombstone blanks the comment but keeps it so its replies stay reachable.
+	// ErrNotFound reports a comment that is missing or already tombstoned.
+	Tombstone(ctx context.Context, id primitive.ObjectID) error
+	IncrementReplyCount(ctx context.Context, id primitive.ObjectID, delta int64) error
+	DeleteByPost(ctx context.Context, postID primitive.ObjectID) error
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCommentRepository struct {
+	mu       sync.RWMutex
+	comments map[primitive.ObjectID]models.Comment
+}
+
+// NewMemoryCommentRepository keeps comments in a map keyed by id
+func NewMemoryCommentRepository() CommentRepository {
+	return &memoryCommentRepository{comments: map[primitive.ObjectID]models.Comment{}}
+}
+
+func (r *memoryCommentRepository) Create(ctx context.Context, comment models.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.comments[comment.ID] = comment
+	return nil
+}
+
+func (r *memoryCommentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	comment, ok := r.comments[id]
+	if !ok {
+		return models.Comment{}, ErrNotFound
+	}
+	return comment, nil
+}
+
+func (r *memoryCommentRepository) List(ctx context.Context, postID primitive.ObjectID, parentID *primitive.ObjectID, page Page) ([]models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	comments := []models.Comment{}
+	for _, comment := range r.comments {
+		sameParent := (comment.ParentID == nil && parentID == nil) ||
+			(comment.ParentID != nil && parentID != nil && *comment.ParentID == *parentID)
+		if comment.PostID == postID && sameParent {
+			comments = append(comments, comment)
+		}
+	}
+	return paginate(comments, page, func(comment models.Comment) primitive.ObjectID { return comment.ID }), nil
+}
+
+func (r *memoryCommentRepository) UpdateContent(ctx context.Context, id primitive.ObjectID, content string) error {
+	return r.update(id, func(comment *models.Comment) {
+		now := time.Now()
+		comment.Content = content
+		comment.EditedAt = &now
+	})
+}
+
+func (r *memoryCommentRepository) Tombstone(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comment, ok := r.comments[id]
+	if !ok || comment.Deleted {
+		return ErrNotFound
+	}
+	comment.Content = ""
+	comment.Deleted = true
+	r.comments[id] = comment
+	return nil
+}
+
+func (r *memoryCommentRepository) IncrementReplyCount(ctx context.Context, id primitive.ObjectID, delta int64) error {
+	return r.update(id, func(comment *models.Comment) {
+		comment.ReplyCount += delta
+	})
+}
+
+func (r *memoryCommentRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, comment := range r.comments {
+		if comment.PostID == postID {
+			delete(r.comments, id)
+		}
+	}
+	return nil
+}
+
+func (r *memoryCommentRepository) update(id primitive.ObjectID, apply func(*models.Comment)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comment, ok := r.comments[id]
+	if !ok {
+		return ErrNotFound
+	}
+	apply(&comment)
+	r.comments[id] = comment
+	return nil
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoCommentRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCommentRepository stores comments in the "comments" collection of db
+func NewMongoCommentRepository(db *mongo.Database) CommentRepository {
+	return &mongoCommentRepository{collection: db.Collection("comments")}
+}
+
+func (r *mongoCommentRepository) Create(ctx context.Context, comment models.Comment) error {
+	_, err := r.collection.InsertOne(ctx, comment)
+	return err
+}
+
+func (r *mongoCommentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Comment, error) {
+	var comment models.Comment
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&comment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return comment, ErrNotFound
+	}
+	return comment, err
+}
+
+func (r *mongoCommentRepository) List(ctx context.Context, postID primitive.ObjectID, parentID *primitive.ObjectID, page Page) ([]models.Comment, error) {
+	return findPage[models.Comment](ctx, r.collection, bson.M{"post_id": postID, "parent_id": parentID}, "_id", page)
+}
+
+func (r *mongoCommentRepository) UpdateContent(ctx context.Context, id primitive.ObjectID, content string) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"content": content, "edited_at": time.Now()}})
+}
+
+func (r *mongoCommentRepository) Tombstone(ctx context.Context, id primitive.ObjectID) error {
+	// Only the first of concurrent deletes matches, so the comment count drops once
+	return r.updateOne(ctx, bson.M{"_id": id, "deleted": bson.M{"$ne": true}}, bson.M{"$set": bson.M{"content": "", "deleted": true}})
+}
+
+func (r *mongoCommentRepository) IncrementReplyCount(ctx context.Context, id primitive.ObjectID, delta int64) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"reply_count": delta}})
+}
+
+func (r *mongoCommentRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
+
+func (r *mongoCommentRepository) updateOne(ctx context.Context, filter bson.M, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FollowRepository stores the follow graph between users
+type FollowRepository interface {
+	// Follow adds the edge; following the same user twice is a no-op
+	Follow(ctx context.Context, follow models.Follow) error
+	Unfollow(ctx context.Context, followerID primitive.ObjectID, followeeID primitive.ObjectID) error
+	IsFollowing(ctx context.Context, followerID primitive.ObjectID, followeeID primitive.ObjectID) (bool, error)
+	// Followers returns up to page.Limit edges pointing at userID, most recent first
+	Followers(ctx context.Context, userID primitive.ObjectID, page Page) ([]models.Follow, error)
+	// Following returns up to page.Limit edges starting at userID, most recent first
+	Following(ctx context.Context, userID primitive.ObjectID, page Page) ([]models.Follow, error)
+	// FollowingIDs returns the ids of every user userID follows
+	FollowingIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	// FollowerIDs returns the ids of every user following userID
+	FollowerIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	CountFollowers(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	CountFollowing(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type followKey struct {
+	follower primitive.ObjectID
+	followee primitive.ObjectID
+}
+
+type memoryFollowRepository struct {
+	mu      sync.RWMutex
+	follows map[followKey]models.Follow
+}
+
+// NewMemoryFollowRepository keeps follow edges in a map keyed by (follower, followee)
+func NewMemoryFollowRepository() FollowRepository {
+	return &memoryFollowRepository{follows: map[followKey]models.Follow{}}
+}
+
+func (r *memoryFollowRepository) Follow(ctx context.Context, follow models.Follow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := followKey{follow.FollowerID, follow.FolloweeID}
+	if _, ok := r.follows[key]; !ok {
+		r.follows[key] = follow
+	}
+	return nil
+}
+
+func (r *memoryFollowRepository) Unfollow(ctx context.Context, followerID primitive.ObjectID, followeeID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := followKey{followerID, followeeID}
+	if _, ok := r.follows[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.follows, key)
+	return nil
+}
+
+func (r *memoryFollowRepository) IsFollowing(ctx context.Context, followerID primitive.ObjectID, followeeID primitive.ObjectID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.follows[followKey{followerID, followeeID}]
+	return ok, nil
+}
+
+func (r *memoryFollowRepository) Followers(ctx context.Context, userID primitive.ObjectID, page Page) ([]models.Follow, error) {
+	return paginate(r.filter(func(f models.Follow) bool { return f.FolloweeID == userID }), page, followID), nil
+}
+
+func (r *memoryFollowRepository) Following(ctx context.Context, userID primitive.ObjectID, page Page) ([]models.Follow, error) {
+	return paginate(r.filter(func(f models.Follow) bool { return f.FollowerID == userID }), page, followID), nil
+}
+
+func (r *memoryFollowRepository) FollowingIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	ids := []primitive.ObjectID{}
+	for _, follow := range r.filter(func(f models.Follow) bool { return f.FollowerID == userID }) {
+		ids = append(ids, follow.FolloweeID)
+	}
+	return ids, nil
+}
+
+func (r *memoryFollowRepository) FollowerIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	ids := []primitive.ObjectID{}
+	for _, follow := range r.filter(func(f models.Follow) bool { return f.FolloweeID == userID }) {
+		ids = append(ids, follow.FollowerID)
+	}
+	return ids, nil
+}
+
+func (r *memoryFollowRepository) CountFollowers(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return int64(len(r.filter(func(f models.Follow) bool { return f.FolloweeID == userID }))), nil
+}
+
+func (r *memoryFollowRepository) CountFollowing(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return int64(len(r.filter(func(f models.Follow) bool { return f.FollowerID == userID }))), nil
+}
+
+func (r *memoryFollowRepository) filter(match func(models.Follow) bool) []models.Follow {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	follows := []models.Follow{}
+	for _, follow := range r.follows {
+		if match(follow) {
+			follows = append(follows, follow)
+		}
+	}
+	return follows
+}
+
+func followID(follow models.Follow) primitive.ObjectID {
+	return follow.ID
+}
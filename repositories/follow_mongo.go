@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoFollowRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoFollowRepository stores follow edges in the "follows" collection of db
+func NewMongoFollowRepository(db *mongo.Database) FollowRepository {
+	return &mongoFollowRepository{collection: db.Collection("follows")}
+}
+
+func (r *mongoFollowRepository) Follow(ctx context.Context, follow models.Follow) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"follower_id": follow.FollowerID, "followee_id": follow.FolloweeID},
+		bson.M{"$setOnInsert": follow},
+		options.Update().SetUpsert(true),
+	)
+	// A concurrent request inserted the same edge first
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *mongoFollowRepository) Unfollow(ctx context.Context, followerID primitive.ObjectID, followeeID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"follower_id": followerID, "followee_id": followeeID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoFollowRepository) IsFollowing(ctx context.Context, followerID primitive.ObjectID, followeeID primitive.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"follower_id": followerID, "followee_id": followeeID}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+func (r *mongoFollowRepository) Followers(ctx context.Context, userID primitive.ObjectID, page Page) ([]models.Follow, error) {
+	return r.list(ctx, "followee_id", userID, page)
+}
+
+func (r *mongoFollowRepository) Following(ctx context.Context, userID primitive.ObjectID, page Page) ([]models.Follow, error) {
+	return r.list(ctx, "follower_id", userID, page)
+}
+
+func (r *mongoFollowRepository) list(ctx context.Context, field string, userID primitive.ObjectID, page Page) ([]models.Follow, error) {
+	return findPage[models.Follow](ctx, r.collection, bson.M{field: userID}, "_id", page)
+}
+
+func (r *mongoFollowRepository) FollowingIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return r.ids(ctx, bson.M{"follower_id": userID}, "followee_id")
+}
+
+func (r *mongoFollowRepository) FollowerIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return r.ids(ctx, bson.M{"followee_id": userID}, "follower_id")
+}
+
+// ids returns field of every edge matching filter
+func (r *mongoFollowRepository) ids(ctx context.Context, filter bson.M, field string) ([]primitive.ObjectID, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{field: 1}))
+	if err != nil {
+		return nil, err
+	}
+	var follows []models.Follow
+	if err = cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		if field == "follower_id" {
+			ids = append(ids, follow.FollowerID)
+		} else {
+			ids = append(ids, follow.FolloweeID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *mongoFollowRepository) CountFollowers(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"followee_id": userID})
+}
+
+func (r *mongoFollowRepository) CountFollowing(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"follower_id": userID})
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+)
+
+// MigrationRepository records which schema migrations have been applied
+type MigrationRepository interface {
+	// Applied returns every applied migration, lowest version first
+	Applied(ctx context.Context) ([]models.MigrationRecord, error)
+	// Record notes record.Version as applied; recording it twice is a no-op
+	Record(ctx context.Context, record models.MigrationRecord) error
+	// Remove notes version as reverted
+	Remove(ctx context.Context, version int) error
+}
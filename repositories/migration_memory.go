@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/VisarutJDev/social-media-api/models"
+)
+
+type memoryMigrationRepository struct {
+	mu      sync.RWMutex
+	records map[int]models.MigrationRecord
+}
+
+// NewMemoryMigrationRepository keeps migration records in a map keyed by version
+func NewMemoryMigrationRepository() MigrationRepository {
+	return &memoryMigrationRepository{records: map[int]models.MigrationRecord{}}
+}
+
+func (r *memoryMigrationRepository) Applied(ctx context.Context) ([]models.MigrationRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	records := make([]models.MigrationRecord, 0, len(r.records))
+	for _, record := range r.records {
+		records = append(records, record)
+	}
+	slices.SortFunc(records, func(a, b models.MigrationRecord) int { return cmp.Compare(a.Version, b.Version) })
+	return records, nil
+}
+
+func (r *memoryMigrationRepository) Record(ctx context.Context, record models.MigrationRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.records[record.Version]; !ok {
+		r.records[record.Version] = record
+	}
+	return nil
+}
+
+func (r *memoryMigrationRepository) Remove(ctx context.Context, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, version)
+	return nil
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoMigrationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoMigrationRepository records migrations in the "migrations" collection of db
+func NewMongoMigrationRepository(db *mongo.Database) MigrationRepository {
+	return &mongoMigrationRepository{collection: db.Collection("migrations")}
+}
+
+func (r *mongoMigrationRepository) Applied(ctx context.Context) ([]models.MigrationRecord, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	records := []models.MigrationRecord{}
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *mongoMigrationRepository) Record(ctx context.Context, record models.MigrationRecord) error {
+	_, err := r.collection.InsertOne(ctx, record)
+	// Another instance applied the same migration concurrently
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *mongoMigrationRepository) Remove(ctx context.Context, version int) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": version})
+	return err
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostRepository stores posts. Posts in the trash are left out of every lookup
+// and list but those named for the trash.
+type PostRepository interface {
+	Create(ctx context.Context, post models.Post) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Post, error)
+	// FindDeleted returns the post with id only while it is in the trash
+	FindDeleted(ctx context.Context, id primitive.ObjectID) (models.Post, error)
+	// List returns up to page.Limit posts, newest first or by page.SortBy, one of models.PostSorts
+	List(ctx context.Context, page Page) ([]models.Post, error)
+	// ListByAuthors returns up to page.Limit posts written by any of authorIDs, newest first
+	ListByAuthors(ctx context.Context, authorIDs []primitive.ObjectID, page Page) ([]models.Post, error)
+	// FindByIDs returns the posts among ids that exist, in no particular order
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Post, error)
+	// Update stores the title, content, UpdatedAt and EditedAt of post while the
+	// stored post is at version, moving it to the next version; the other fields
+	// are kept. ErrVersionConflict reports a post that has moved on.
+	Update(ctx context.Context, id primitive.ObjectID, version int64, post models.Post) error
+	// SoftDelete moves the post to the trash while it is at version, recording when and by whom
+	SoftDelete(ctx context.Context, id primitive.ObjectID, version int64, deletedBy string, at time.Time) error
+	// Restore takes the post out of the trash, setting UpdatedAt to at and moving it to the next version
+	Restore(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// ListDeleted returns up to page.Limit posts of authorID in the trash, most recently deleted first
+	ListDeleted(ctx context.Context, authorID primitive.ObjectID, page Page) ([]models.Post, error)
+	// ListDeletedBefore returns up to limit posts moved to the trash before cutoff, oldest first
+	ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.Post, error)
+	// Delete removes the post for good, whether it is in the trash or not
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	IncrementCommentCount(ctx context.Context, id primitive.ObjectID, delta int64) error
+	// IncrementReactionCounts adds deltas, keyed by reaction type, to the post's counts in one update
+	IncrementReactionCounts(ctx context.Context, id primitive.ObjectID, deltas map[string]int64) error
+}
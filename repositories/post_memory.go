@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPostRepository struct {
+	mu    sync.RWMutex
+	posts map[primitive.ObjectID]models.Post
+}
+
+// NewMemoryPostRepository keeps posts in a map keyed by id
+func NewMemoryPostRepository() PostRepository {
+	return &memoryPostRepository{posts: map[primitive.ObjectID]models.Post{}}
+}
+
+func (r *memoryPostRepository) Create(ctx context.Context, post models.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.posts[post.ID] = post
+	return nil
+}
+
+func (r *memoryPostRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	post, ok := r.posts[id]
+	if !ok || post.DeletedAt != nil {
+		return models.Post{}, ErrNotFound
+	}
+	return post, nil
+}
+
+func (r *memoryPostRepository) FindDeleted(ctx context.Context, id primitive.ObjectID) (models.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	post, ok := r.posts[id]
+	if !ok || post.DeletedAt == nil {
+		return models.Post{}, ErrNotFound
+	}
+	return post, nil
+}
+
+func (r *memoryPostRepository) List(ctx context.Context, page Page) ([]models.Post, error) {
+	return paginateBy(r.filter(live), page, postID, postTime), nil
+}
+
+func (r *memoryPostRepository) ListByAuthors(ctx context.Context, authorIDs []primitive.ObjectID, page Page) ([]models.Post, error) {
+	posts := r.filter(func(post models.Post) bool { return live(post) && slices.Contains(authorIDs, post.AuthorID) })
+	return paginateBy(posts, page, postID, postTime), nil
+}
+
+func (r *memoryPostRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Post, error) {
+	return r.filter(func(post models.Post) bool { return live(post) && slices.Contains(ids, post.ID) }), nil
+}
+
+func (r *memoryPostRepository) Update(ctx context.Context, id primitive.ObjectID, version int64, post models.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.posts[id]
+	if !ok || existing.DeletedAt != nil {
+		return ErrNotFound
+	}
+	if existing.Version != version {
+		return ErrVersionConflict
+	}
+	existing.Version++
+	existing.Title = post.Title
+	existing.Content = post.Content
+	existing.UpdatedAt = post.UpdatedAt
+	existing.EditedAt = post.EditedAt
+	r.posts[id] = existing
+	return nil
+}
+
+func (r *memoryPostRepository) SoftDelete(ctx context.Context, id primitive.ObjectID, version int64, deletedBy string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	post, ok := r.posts[id]
+	if !ok || post.DeletedAt != nil {
+		return ErrNotFound
+	}
+	if post.Version != version {
+		return ErrVersionConflict
+	}
+	post.Version++
+	post.DeletedAt = &at
+	post.DeletedBy = deletedBy
+	post.UpdatedAt = at
+	r.posts[id] = post
+	return nil
+}
+
+func (r *memoryPostRepository) Restore(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	post, ok := r.posts[id]
+	if !ok || post.DeletedAt == nil {
+		return ErrNotFound
+	}
+	post.DeletedAt = nil
+	post.DeletedBy = ""
+	post.UpdatedAt = at
+	post.Version++
+	r.posts[id] = post
+	return nil
+}
+
+func (r *memoryPostRepository) ListDeleted(ctx context.Context, authorID primitive.ObjectID, page Page) ([]models.Post, error) {
+	posts := r.filter(func(post models.Post) bool { return !live(post) && post.AuthorID == authorID })
+	page.SortBy = models.SortDeletedAt
+	return paginateBy(posts, page, postID, postTime), nil
+}
+
+func (r *memoryPostRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.Post, error) {
+	posts := r.filter(func(post models.Post) bool { return !live(post) && post.DeletedAt.Before(cutoff) })
+	slices.SortFunc(posts, func(a, b models.Post) int { return a.DeletedAt.Compare(*b.DeletedAt) })
+	return posts[:min(len(posts), limit)], nil
+}
+
+func (r *memoryPostRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.posts[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.posts, id)
+	return nil
+}
+
+func (r *memoryPostRepository) IncrementCommentCount(ctx context.Context, id primitive.ObjectID, delta int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	post, ok := r.posts[id]
+	if !ok {
+		return ErrNotFound
+	}
+	post.CommentCount += delta
+	r.posts[id] = post
+	return nil
+}
+
+func (r *memoryPostRepository) IncrementReactionCounts(ctx context.Context, id primitive.ObjectID, deltas map[string]int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	post, ok := r.posts[id]
+	if !ok {
+		return ErrNotFound
+	}
+	// Copy so posts handed out earlier keep the counts they were read with
+	reactions := map[string]int64{}
+	for reactionType, count := range post.Reactions {
+		reactions[reactionType] = count
+	}
+	for reactionType, delta := range deltas {
+		reactions[reactionType] += delta
+	}
+	post.Reactions = reactions
+	r.posts[id] = post
+	return nil
+}
+
+func (r *memoryPostRepository) filter(match func(models.Post) bool) []models.Post {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	posts := []models.Post{}
+	for _, post := range r.posts {
+		if match(post) {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
+
+// live reports whether post is out of the trash
+func live(post models.Post) bool {
+	return post.DeletedAt == nil
+}
+
+func postID(post models.Post) primitive.ObjectID {
+	return post.ID
+}
+
+func postTime(post models.Post, sort string) (time.Time, bool) {
+	return post.SortTime(sort)
+}
@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// notDeleted matches the posts out of the trash, which have no deleted_at
+var notDeleted = bson.M{"deleted_at": nil}
+
+// inTrash matches the posts in the trash
+var inTrash = bson.M{"deleted_at": bson.M{"$type": "date"}}
+
+// and adds the conditions of match to filter
+func and(filter bson.M, match bson.M) bson.M {
+	for key, value := range match {
+		filter[key] = value
+	}
+	return filter
+}
+
+type mongoPostRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoPostRepository stores posts in the "posts" collection of db
+func NewMongoPostRepository(db *mongo.Database) PostRepository {
+	return &mongoPostRepository{collection: db.Collection("posts")}
+}
+
+func (r *mongoPostRepository) Create(ctx context.Context, post models.Post) error {
+	_, err := r.collection.InsertOne(ctx, post)
+	return err
+}
+
+func (r *mongoPostRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Post, error) {
+	var post models.Post
+	err := r.collection.FindOne(ctx, and(bson.M{"_id": id}, notDeleted)).Decode(&post)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return post, ErrNotFound
+	}
+	return post, err
+}
+
+func (r *mongoPostRepository) FindDeleted(ctx context.Context, id primitive.ObjectID) (models.Post, error) {
+	var post models.Post
+	err := r.collection.FindOne(ctx, and(bson.M{"_id": id}, inTrash)).Decode(&post)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return post, ErrNotFound
+	}
+	return post, err
+}
+
+func (r *mongoPostRepository) List(ctx context.Context, page Page) ([]models.Post, error) {
+	return findPage[models.Post](ctx, r.collection, and(bson.M{}, notDeleted), "_id", page)
+}
+
+func (r *mongoPostRepository) ListByAuthors(ctx context.Context, authorIDs []primitive.ObjectID, page Page) ([]models.Post, error) {
+	return findPage[models.Post](ctx, r.collection, and(bson.M{"author_id": bson.M{"$in": authorIDs}}, notDeleted), "_id", page)
+}
+
+func (r *mongoPostRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Post, error) {
+	cursor, err := r.collection.Find(ctx, and(bson.M{"_id": bson.M{"$in": ids}}, notDeleted))
+	if err != nil {
+		return nil, err
+	}
+	posts := []models.Post{}
+	err = cursor.All(ctx, &posts)
+	return posts, err
+}
+
+func (r *mongoPostRepository) Update(ctx context.Context, id primitive.ObjectID, version int64, post models.Post) error {
+	result, err := r.collection.UpdateOne(ctx, and(bson.M{"_id": id, "version": version}, notDeleted), bson.M{"$set": bson.M{
+		"title":      post.Title,
+		"content":    post.Content,
+		"updated_at": post.UpdatedAt,
+		"edited_at":  post.EditedAt,
+		"version":    version + 1,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.missed(ctx, id)
+	}
+	return nil
+}
+
+// missed tells why a versioned write of the live post id matched nothing
+func (r *mongoPostRepository) missed(ctx context.Context, id primitive.ObjectID) error {
+	count, err := r.collection.CountDocuments(ctx, and(bson.M{"_id": id}, notDeleted))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
+}
+
+func (r *mongoPostRepository) SoftDelete(ctx context.Context, id primitive.ObjectID, version int64, deletedBy string, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, and(bson.M{"_id": id, "version": version}, notDeleted), bson.M{"$set": bson.M{
+		"deleted_at": at,
+		"deleted_by": deletedBy,
+		"updated_at": at,
+		"version":    version + 1,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.missed(ctx, id)
+	}
+	return nil
+}
+
+func (r *mongoPostRepository) Restore(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, and(bson.M{"_id": id}, inTrash), bson.M{
+		"$set":   bson.M{"updated_at": at},
+		"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+		"$inc":   bson.M{"version": 1},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPostRepository) ListDeleted(ctx context.Context, authorID primitive.ObjectID, page Page) ([]models.Post, error) {
+	page.SortBy = models.SortDeletedAt
+	return findPage[models.Post](ctx, r.collection, bson.M{"author_id": authorID}, "_id", page)
+}
+
+func (r *mongoPostRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.Post, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"deleted_at": bson.M{"$lt": cutoff}}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	posts := []models.Post{}
+	err = cursor.All(ctx, &posts)
+	return posts, err
+}
+
+func (r *mongoPostRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPostRepository) IncrementCommentCount(ctx context.Context, id primitive.ObjectID, delta int64) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"comment_count": delta}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPostRepository) IncrementReactionCounts(ctx context.Context, id primitive.ObjectID, deltas map[string]int64) error {
+	inc := bson.M{}
+	for reactionType, delta := range deltas {
+		inc["reactions."+reactionType] = delta
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": inc})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
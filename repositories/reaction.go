@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReactionRepository stores the reaction each user gave a post
+type ReactionRepository interface {
+	// Set stores reaction as its user's only reaction to the post and returns the type it
+	// replaced, or "" if the user had not reacted. Callers adjust the post counters from
+	// the returned type, which is read atomically with the write.
+	Set(ctx context.Context, reaction models.Reaction) (string, error)
+	// Remove deletes the user's reaction to the post and returns its type, or ErrNotFound
+	Remove(ctx context.Context, postID primitive.ObjectID, userID primitive.ObjectID) (string, error)
+	// ForUser returns the reaction types userID gave to any of postIDs, keyed by post id
+	ForUser(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) (map[primitive.ObjectID]string, error)
+	DeleteByPost(ctx context.Context, postID primitive.ObjectID) error
+}
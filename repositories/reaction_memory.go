@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type reactionKey struct {
+	postID primitive.ObjectID
+	userID primitive.ObjectID
+}
+
+type memoryReactionRepository struct {
+	mu        sync.Mutex
+	reactions map[reactionKey]models.Reaction
+}
+
+// NewMemoryReactionRepository keeps reactions in a map keyed by post and user
+func NewMemoryReactionRepository() ReactionRepository {
+	return &memoryReactionRepository{reactions: map[reactionKey]models.Reaction{}}
+}
+
+func (r *memoryReactionRepository) Set(ctx context.Context, reaction models.Reaction) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := reactionKey{reaction.PostID, reaction.UserID}
+	previous, ok := r.reactions[key]
+	if ok {
+		// Like the Mongo upsert, the original id survives a change of type
+		reaction.ID = previous.ID
+	}
+	r.reactions[key] = reaction
+	return previous.Type, nil
+}
+
+func (r *memoryReactionRepository) Remove(ctx context.Context, postID primitive.ObjectID, userID primitive.ObjectID) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := reactionKey{postID, userID}
+	reaction, ok := r.reactions[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	delete(r.reactions, key)
+	return reaction.Type, nil
+}
+
+func (r *memoryReactionRepository) ForUser(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := map[primitive.ObjectID]string{}
+	for key, reaction := range r.reactions {
+		if key.userID == userID && slices.Contains(postIDs, key.postID) {
+			types[key.postID] = reaction.Type
+		}
+	}
+	return types, nil
+}
+
+func (r *memoryReactionRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.reactions {
+		if key.postID == postID {
+			delete(r.reactions, key)
+		}
+	}
+	return nil
+}
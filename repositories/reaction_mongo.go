@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoReactionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoReactionRepository stores reactions in the "reactions" collection of db
+func NewMongoReactionRepository(db *mongo.Database) ReactionRepository {
+	return &mongoReactionRepository{collection: db.Collection("reactions")}
+}
+
+func (r *mongoReactionRepository) Set(ctx context.Context, reaction models.Reaction) (string, error) {
+	filter := bson.M{"post_id": reaction.PostID, "user_id": reaction.UserID}
+	update := bson.M{
+		"$set":         bson.M{"type": reaction.Type, "created_at": reaction.CreatedAt},
+		"$setOnInsert": bson.M{"_id": reaction.ID},
+	}
+	findOptions := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var previous models.Reaction
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, findOptions).Decode(&previous)
+	// A concurrent request inserted the same reaction first; the retry updates it instead
+	if mongo.IsDuplicateKeyError(err) {
+		err = r.collection.FindOneAndUpdate(ctx, filter, update, findOptions).Decode(&previous)
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	return previous.Type, err
+}
+
+func (r *mongoReactionRepository) Remove(ctx context.Context, postID primitive.ObjectID, userID primitive.ObjectID) (string, error) {
+	var reaction models.Reaction
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"post_id": postID, "user_id": userID}).Decode(&reaction)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", ErrNotFound
+	}
+	return reaction.Type, err
+}
+
+func (r *mongoReactionRepository) ForUser(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID, "post_id": bson.M{"$in": postIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var reactions []models.Reaction
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+	types := map[primitive.ObjectID]string{}
+	for _, reaction := range reactions {
+		types[reaction.PostID] = reaction.Type
+	}
+	return types, nil
+}
+
+func (r *mongoReactionRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
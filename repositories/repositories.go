@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNotFound is returned when no document matches the lookup
+var ErrNotFound = errors.New("not found")
+
+// ErrDuplicate is returned when a unique index already holds the value being stored
+var ErrDuplicate = errors.New("duplicate")
+
+// ErrVersionConflict is returned when a document has moved past the version a write expects
+var ErrVersionConflict = errors.New("version conflict")
+
+// Page selects a window of documents ordered by _id descending (newest first),
+// or by the time field SortBy and then _id when SortBy is set
+type Page struct {
+	Limit    int
+	Cursor   primitive.ObjectID // Zero for the first page
+	Prev     bool               // Read the documents newer than Cursor instead of older
+	SortBy   string             // Time field such as "updated_at"; documents without it are left out
+	CursorAt time.Time          // SortBy of the document at Cursor
+}
+
+// Repositories bundles every repository the handlers need
+type Repositories struct {
+	Users         UserRepository
+	Posts         PostRepository
+	RefreshTokens RefreshTokenRepository
+	Denylist      DenylistRepository
+	Follows       FollowRepository
+	Timelines     TimelineRepository
+	Comments      CommentRepository
+	Reactions     ReactionRepository
+	Revisions     RevisionRepository
+}
+
+// NewMongoRepositories returns repositories backed by collections in db
+func NewMongoRepositories(db *mongo.Database) *Repositories {
+	return &Repositories{
+		Users:         NewMongoUserRepository(db),
+		Posts:         NewMongoPostRepository(db),
+		RefreshTokens: NewMongoRefreshTokenRepository(db),
+		Denylist:      NewMongoDenylistRepository(db),
+		Follows:       NewMongoFollowRepository(db),
+		Timelines:     NewMongoTimelineRepository(db),
+		Comments:      NewMongoCommentRepository(db),
+		Reactions:     NewMongoReactionRepository(db),
+		Revisions:     NewMongoRevisionRepository(db),
+	}
+}
+
+// NewMemoryRepositories returns repositories that keep everything in process memory
+func NewMemoryRepositories() *Repositories {
+	return &Repositories{
+		Users:         NewMemoryUserRepository(),
+		Posts:         NewMemoryPostRepository(),
+		RefreshTokens: NewMemoryRefreshTokenRepository(),
+		Denylist:      NewMemoryDenylistRepository(),
+		Follows:       NewMemoryFollowRepository(),
+		Timelines:     NewMemoryTimelineRepository(),
+		Comments:      NewMemoryCommentRepository(),
+		Reactions:     NewMemoryReactionRepository(),
+		Revisions:     NewMemoryRevisionRepository(),
+	}
+}
+
+// findPage runs filter narrowed to page, where field holds the ids the page is
+// ordered by, and returns the documents newest first
+func findPage[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, field string, page Page) ([]T, error) {
+	sort := -1
+	operator := "$lt"
+	if page.Prev {
+		// Newer documents are read ascending from the cursor and reversed below
+		sort = 1
+		operator = "$gt"
+	}
+	order := bson.D{{Key: field, Value: sort}}
+	if page.SortBy == "" {
+		if !page.Cursor.IsZero() {
+			filter[field] = bson.M{operator: page.Cursor}
+		}
+	} else {
+		filter[page.SortBy] = bson.M{"$type": "date"}
+		if !page.Cursor.IsZero() {
+			// Past the cursor's time, or at the same time past its id
+			filter["$or"] = bson.A{
+				bson.M{page.SortBy: bson.M{operator: page.CursorAt}},
+				bson.M{page.SortBy: page.CursorAt, field: bson.M{operator: page.Cursor}},
+			}
+		}
+		order = bson.D{{Key: page.SortBy, Value: sort}, {Key: field, Value: sort}}
+	}
+	findOptions := options.Find()
+	findOptions.SetSort(order)
+	findOptions.SetLimit(int64(page.Limit))
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	items := []T{}
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if sort > 0 {
+		slices.Reverse(items)
+	}
+	return items, nil
+}
+
+// paginate applies page to items in memory the same way findPage does in Mongo
+func paginate[T any](items []T, page Page, id func(T) primitive.ObjectID) []T {
+	return paginateBy(items, page, id, nil)
+}
+
+// paginateBy is paginate for pages that may be sorted by time. at reads the
+// page.SortBy field of an item and whether it is set.
+func paginateBy[T any](items []T, page Page, id func(T) primitive.ObjectID, at func(T, string) (time.Time, bool)) []T {
+	type key struct {
+		at time.Time
+		id primitive.ObjectID
+	}
+	keyOf := func(item T) key {
+		k := key{id: id(item)}
+		if page.SortBy != "" {
+			k.at, _ = at(item, page.SortBy)
+		}
+		return k
+	}
+	newer := func(a, b key) int {
+		if c := a.at.Compare(b.at); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.id[:], b.id[:])
+	}
+	if page.SortBy != "" {
+		items = slices.DeleteFunc(items, func(item T) bool {
+			_, ok := at(item, page.SortBy)
+			return !ok
+		})
+	}
+	slices.SortFunc(items, func(a, b T) int { return newer(keyOf(b), keyOf(a)) })
+	if page.Cursor.IsZero() {
+		return items[:min(len(items), page.Limit)]
+	}
+	cursor := key{at: page.CursorAt, id: page.Cursor}
+	if page.Prev {
+		end := 0
+		for end < len(items) && newer(keyOf(items[end]), cursor) > 0 {
+			end++
+		}
+		return items[max(0, end-page.Limit):end]
+	}
+	start := 0
+	for start < len(items) && newer(keyOf(items[start]), cursor) >= 0 {
+		start++
+	}
+	return items[start:min(len(items), start+page.Limit)]
+}
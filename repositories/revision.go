@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RevisionRepository stores the edit history of posts. Revisions are never changed.
+type RevisionRepository interface {
+	// Create stores revision, or returns ErrDuplicate when the post already has its number
+	Create(ctx context.Context, revision models.Revision) error
+	Find(ctx context.Context, postID primitive.ObjectID, number int) (models.Revision, error)
+	// Latest returns the revision of postID with the highest number, or ErrNotFound when it has none
+	Latest(ctx context.Context, postID primitive.ObjectID) (models.Revision, error)
+	// List returns up to page.Limit revisions of postID, newest first
+	List(ctx context.Context, postID primitive.ObjectID, page Page) ([]models.Revision, error)
+	DeleteByPost(ctx context.Context, postID primitive.ObjectID) error
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type revisionKey struct {
+	postID primitive.ObjectID
+	number int
+}
+
+type memoryRevisionRepository struct {
+	mu        sync.RWMutex
+	revisions map[revisionKey]models.Revision
+}
+
+// NewMemoryRevisionRepository keeps revisions in a map keyed by post and number
+func NewMemoryRevisionRepository() RevisionRepository {
+	return &memoryRevisionRepository{revisions: map[revisionKey]models.Revision{}}
+}
+
+func (r *memoryRevisionRepository) Create(ctx context.Context, revision models.Revision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := revisionKey{revision.PostID, revision.Number}
+	if _, ok := r.revisions[key]; ok {
+		return ErrDuplicate
+	}
+	r.revisions[key] = revision
+	return nil
+}
+
+func (r *memoryRevisionRepository) Find(ctx context.Context, postID primitive.ObjectID, number int) (models.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	revision, ok := r.revisions[revisionKey{postID, number}]
+	if !ok {
+		return revision, ErrNotFound
+	}
+	return revision, nil
+}
+
+func (r *memoryRevisionRepository) Latest(ctx context.Context, postID primitive.ObjectID) (models.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var latest models.Revision
+	for key, revision := range r.revisions {
+		if key.postID == postID && revision.Number > latest.Number {
+			latest = revision
+		}
+	}
+	if latest.Number == 0 {
+		return latest, ErrNotFound
+	}
+	return latest, nil
+}
+
+func (r *memoryRevisionRepository) List(ctx context.Context, postID primitive.ObjectID, page Page) ([]models.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	revisions := []models.Revision{}
+	for key, revision := range r.revisions {
+		if key.postID == postID {
+			revisions = append(revisions, revision)
+		}
+	}
+	return paginate(revisions, page, func(revision models.Revision) primitive.ObjectID { return revision.ID }), nil
+}
+
+func (r *memoryRevisionRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.revisions {
+		if key.postID == postID {
+			delete(r.revisions, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRevisionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoRevisionRepository stores revisions in the "revisions" collection of db
+func NewMongoRevisionRepository(db *mongo.Database) RevisionRepository {
+	return &mongoRevisionRepository{collection: db.Collection("revisions")}
+}
+
+func (r *mongoRevisionRepository) Create(ctx context.Context, revision models.Revision) error {
+	_, err := r.collection.InsertOne(ctx, revision)
+	// The unique index on post and number catches concurrent edits
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *mongoRevisionRepository) Find(ctx context.Context, postID primitive.ObjectID, number int) (models.Revision, error) {
+	return r.findOne(ctx, bson.M{"post_id": postID, "number": number})
+}
+
+func (r *mongoRevisionRepository) Latest(ctx context.Context, postID primitive.ObjectID) (models.Revision, error) {
+	return r.findOne(ctx, bson.M{"post_id": postID}, options.FindOne().SetSort(bson.D{{Key: "number", Value: -1}}))
+}
+
+func (r *mongoRevisionRepository) List(ctx context.Context, postID primitive.ObjectID, page Page) ([]models.Revision, error) {
+	return findPage[models.Revision](ctx, r.collection, bson.M{"post_id": postID}, "_id", page)
+}
+
+func (r *mongoRevisionRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
+
+func (r *mongoRevisionRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (models.Revision, error) {
+	var revision models.Revision
+	err := r.collection.FindOne(ctx, filter, opts...).Decode(&revision)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return revision, ErrNotFound
+	}
+	return revision, err
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimelineRepository stores precomputed home timelines
+type TimelineRepository interface {
+	// Add stores entries; an entry already on a timeline is kept once
+	Add(ctx context.Context, entries []models.TimelineEntry) error
+	// List returns up to page.Limit entries of userID's timeline, ordered by post id newest first
+	List(ctx context.Context, userID primitive.ObjectID, page Page) ([]models.TimelineEntry, error)
+	// RemovePost takes a post off every timeline
+	RemovePost(ctx context.Context, postID primitive.ObjectID) error
+	// RemoveAuthor takes the posts of authorID off userID's timeline
+	RemoveAuthor(ctx context.Context, userID primitive.ObjectID, authorID primitive.ObjectID) error
+}
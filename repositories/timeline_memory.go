@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type timelineKey struct {
+	user primitive.ObjectID
+	post primitive.ObjectID
+}
+
+type memoryTimelineRepository struct {
+	mu      sync.RWMutex
+	entries map[timelineKey]models.TimelineEntry
+}
+
+// NewMemoryTimelineRepository keeps timeline entries in a map keyed by (user, post)
+func NewMemoryTimelineRepository() TimelineRepository {
+	return &memoryTimelineRepository{entries: map[timelineKey]models.TimelineEntry{}}
+}
+
+func (r *memoryTimelineRepository) Add(ctx context.Context, entries []models.TimelineEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, entry := range entries {
+		key := timelineKey{entry.UserID, entry.PostID}
+		if _, ok := r.entries[key]; !ok {
+			r.entries[key] = entry
+		}
+	}
+	return nil
+}
+
+func (r *memoryTimelineRepository) List(ctx context.Context, userID primitive.ObjectID, page Page) ([]models.TimelineEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := []models.TimelineEntry{}
+	for _, entry := range r.entries {
+		if entry.UserID == userID {
+			entries = append(entries, entry)
+		}
+	}
+	return paginate(entries, page, func(entry models.TimelineEntry) primitive.ObjectID { return entry.PostID }), nil
+}
+
+func (r *memoryTimelineRepository) RemovePost(ctx context.Context, postID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.entries {
+		if key.post == postID {
+			delete(r.entries, key)
+		}
+	}
+	return nil
+}
+
+func (r *memoryTimelineRepository) RemoveAuthor(ctx context.Context, userID primitive.ObjectID, authorID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, entry := range r.entries {
+		if entry.UserID == userID && entry.AuthorID == authorID {
+			delete(r.entries, key)
+		}
+	}
+	return nil
+}
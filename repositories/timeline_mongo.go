@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoTimelineRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoTimelineRepository stores timeline entries in the "timelines" collection of db
+func NewMongoTimelineRepository(db *mongo.Database) TimelineRepository {
+	return &mongoTimelineRepository{collection: db.Collection("timelines")}
+}
+
+func (r *mongoTimelineRepository) Add(ctx context.Context, entries []models.TimelineEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, 0, len(entries))
+	for _, entry := range entries {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": entry.UserID, "post_id": entry.PostID}).
+			SetUpdate(bson.M{"$setOnInsert": entry}).
+			SetUpsert(true))
+	}
+	_, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (r *mongoTimelineRepository) List(ctx context.Context, userID primitive.ObjectID, page Page) ([]models.TimelineEntry, error) {
+	return findPage[models.TimelineEntry](ctx, r.collection, bson.M{"user_id": userID}, "post_id", page)
+}
+
+func (r *mongoTimelineRepository) RemovePost(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
+
+func (r *mongoTimelineRepository) RemoveAuthor(ctx context.Context, userID primitive.ObjectID, authorID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID, "author_id": authorID})
+	return err
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+)
+
+// RefreshTokenRepository stores issued refresh tokens
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token models.RefreshToken) error
+	// Use marks the token with hash as used and returns it as it was before,
+	// so a non-nil UsedAt on the result means the token had already been rotated
+	Use(ctx context.Context, hash string) (models.RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+// DenylistRepository stores the ids (jti) of access tokens revoked before they
+// expire. Ids are dropped once their token expires.
+type DenylistRepository interface {
+	Deny(ctx context.Context, jti string, expiresAt time.Time) error
+	IsDenied(ctx context.Context, jti string) (bool, error)
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+)
+
+type memoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]models.RefreshToken
+}
+
+// NewMemoryRefreshTokenRepository keeps refresh tokens in a map keyed by hash
+func NewMemoryRefreshTokenRepository() RefreshTokenRepository {
+	return &memoryRefreshTokenRepository{tokens: map[string]models.RefreshToken{}}
+}
+
+func (r *memoryRefreshTokenRepository) Create(ctx context.Context, token models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token.Hash] = token
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) Use(ctx context.Context, hash string) (models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[hash]
+	if !ok {
+		return token, ErrNotFound
+	}
+	if token.UsedAt == nil {
+		used := token
+		now := time.Now()
+		used.UsedAt = &now
+		r.tokens[hash] = used
+	}
+	return token, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for hash, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.tokens[hash] = token
+		}
+	}
+	return nil
+}
+
+type memoryDenylistRepository struct {
+	mu     sync.RWMutex
+	denied map[string]time.Time
+}
+
+// NewMemoryDenylistRepository keeps revoked access token ids in a map
+func NewMemoryDenylistRepository() DenylistRepository {
+	return &memoryDenylistRepository{denied: map[string]time.Time{}}
+}
+
+// Deny also forgets the ids that have expired, as the TTL index does in MongoDB
+func (r *memoryDenylistRepository) Deny(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for denied, expiry := range r.denied {
+		if !expiry.After(now) {
+			delete(r.denied, denied)
+		}
+	}
+	r.denied[jti] = expiresAt
+	return nil
+}
+
+func (r *memoryDenylistRepository) IsDenied(ctx context.Context, jti string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	expiresAt, ok := r.denied[jti]
+	return ok && expiresAt.After(time.Now()), nil
+}
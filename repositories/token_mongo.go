@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRefreshTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoRefreshTokenRepository stores refresh tokens in the "refresh_tokens" collection of db
+func NewMongoRefreshTokenRepository(db *mongo.Database) RefreshTokenRepository {
+	return &mongoRefreshTokenRepository{collection: db.Collection("refresh_tokens")}
+}
+
+func (r *mongoRefreshTokenRepository) Create(ctx context.Context, token models.RefreshToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *mongoRefreshTokenRepository) Use(ctx context.Context, hash string) (models.RefreshToken, error) {
+	// Keep the first used_at so concurrent refreshes with the same token all see it as used but one
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"used_at": bson.M{"$ifNull": bson.A{"$used_at", time.Now()}},
+	}}}}
+	findOptions := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	var token models.RefreshToken
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"hash": hash}, update, findOptions).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return token, ErrNotFound
+	}
+	return token, err
+}
+
+func (r *mongoRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"family_id": familyID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	return err
+}
+
+type mongoDenylistRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDenylistRepository stores revoked access token ids in the "denied_tokens" collection of db
+func NewMongoDenylistRepository(db *mongo.Database) DenylistRepository {
+	return &mongoDenylistRepository{collection: db.Collection("denied_tokens")}
+}
+
+func (r *mongoDenylistRepository) Deny(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": jti},
+		bson.M{"$set": bson.M{"expires_at": expiresAt}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *mongoDenylistRepository) IsDenied(ctx context.Context, jti string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": jti}, options.Count().SetLimit(1))
+	return count > 0, err
+}
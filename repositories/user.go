@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+)
+
+// UserRepository stores registered users
+type UserRepository interface {
+	// Create stores user, or returns ErrDuplicate when the username is taken
+	Create(ctx context.Context, user models.User) error
+	FindByUsername(ctx context.Context, username string) (models.User, error)
+	// List returns up to page.Limit users, newest first
+	List(ctx context.Context, page Page) ([]models.User, error)
+	// SetRole gives username role and bumps UpdatedAt, or returns ErrNotFound
+	SetRole(ctx context.Context, username string, role string) error
+	// SetSuspended suspends or reinstates username and bumps UpdatedAt, or returns ErrNotFound
+	SetSuspended(ctx context.Context, username string, suspended bool) error
+}
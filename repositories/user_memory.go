@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]models.User
+}
+
+// NewMemoryUserRepository keeps users in a map keyed by username
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: map[string]models.User{}}
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.Username]; ok {
+		return ErrDuplicate
+	}
+	r.users[user.Username] = user
+	return nil
+}
+
+func (r *memoryUserRepository) FindByUsername(ctx context.Context, username string) (models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[username]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, page Page) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	return paginate(users, page, func(u models.User) primitive.ObjectID { return u.ID }), nil
+}
+
+func (r *memoryUserRepository) SetRole(ctx context.Context, username string, role string) error {
+	return r.update(username, func(user *models.User) { user.Role = role })
+}
+
+func (r *memoryUserRepository) SetSuspended(ctx context.Context, username string, suspended bool) error {
+	return r.update(username, func(user *models.User) { user.Suspended = suspended })
+}
+
+func (r *memoryUserRepository) update(username string, change func(*models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return ErrNotFound
+	}
+	change(&user)
+	user.UpdatedAt = models.Now()
+	r.users[username] = user
+	return nil
+}
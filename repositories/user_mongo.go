@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VisarutJDev/social-media-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoUserRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoUserRepository stores users in the "users" collection of db
+func NewMongoUserRepository(db *mongo.Database) UserRepository {
+	return &mongoUserRepository{collection: db.Collection("users")}
+}
+
+func (r *mongoUserRepository) Create(ctx context.Context, user models.User) error {
+	_, err := r.collection.InsertOne(ctx, user)
+	// The unique index on username settles concurrent registrations
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *mongoUserRepository) FindByUsername(ctx context.Context, username string) (models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return user, ErrNotFound
+	}
+	return user, err
+}
+
+func (r *mongoUserRepository) List(ctx context.Context, page Page) ([]models.User, error) {
+	return findPage[models.User](ctx, r.collection, bson.M{}, "_id", page)
+}
+
+func (r *mongoUserRepository) SetRole(ctx context.Context, username string, role string) error {
+	return r.set(ctx, username, bson.M{"role": role})
+}
+
+func (r *mongoUserRepository) SetSuspended(ctx context.Context, username string, suspended bool) error {
+	return r.set(ctx, username, bson.M{"suspended": suspended})
+}
+
+func (r *mongoUserRepository) set(ctx context.Context, username string, fields bson.M) error {
+	fields["updated_at"] = models.Now()
+	result, err := r.collection.UpdateOne(ctx, bson.M{"username": username}, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -1,24 +1,123 @@
 package routes
 
 import (
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/apperrors"
+	"github.com/VisarutJDev/social-media-api/auth"
 	"github.com/VisarutJDev/social-media-api/controllers"
 	"github.com/VisarutJDev/social-media-api/middlewares"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/ratelimit"
+	"github.com/VisarutJDev/social-media-api/repositories"
+	"github.com/VisarutJDev/social-media-api/timeline"
 
 	"github.com/gin-gonic/gin"
 )
 
-func InitRoutes(router *gin.Engine) {
+// Rate limited route groups, as named in config.Configuration.RateLimits
+const (
+	RateLimitAuth = "auth" // Registration, login and token refresh
+	RateLimitAPI  = "api"  // Everything behind authentication
+)
+
+// Options tune the routes beyond the repositories and keys they are served with
+type Options struct {
+	ReactionTypes  []string // Reactions users may give posts, models.DefaultReactionTypes when empty
+	Health         *controllers.HealthController
+	RateLimitStore ratelimit.Store            // In memory when nil
+	RateLimits     map[string]ratelimit.Limit // By route group; groups left out are not limited
+	Lockout        *ratelimit.Lockout         // Nil never locks accounts out
+	TrashRetention time.Duration              // How long deleted posts can be restored
+}
+
+func InitRoutes(router *gin.Engine, repos *repositories.Repositories, keys *auth.KeySet, strategy timeline.Strategy, opts Options) {
+	limits := opts.RateLimitStore
+	if limits == nil {
+		limits = ratelimit.NewMemoryStore()
+	}
+	tokenController := controllers.NewTokenController(keys, repos.RefreshTokens, repos.Denylist, repos.Users)
+	userController := controllers.NewUserController(repos.Users, tokenController, opts.Lockout)
+	postController := controllers.NewPostController(repos.Posts, repos.Comments, repos.Reactions, repos.Revisions, repos.Users, strategy, opts.TrashRetention)
+	followController := controllers.NewFollowController(repos.Follows, repos.Users, strategy)
+	timelineController := controllers.NewTimelineController(strategy, repos.Reactions, repos.Users)
+	commentController := controllers.NewCommentController(repos.Comments, repos.Posts, repos.Users)
+	reactionController := controllers.NewReactionController(repos.Reactions, repos.Posts, repos.Users, opts.ReactionTypes)
+	adminController := controllers.NewAdminController(repos.Users, postController)
+
+	router.Use(
+		middlewares.RequestID(),
+		middlewares.Logger(),
+		middlewares.Tracing(),
+		middlewares.Metrics(),
+		middlewares.ErrorHandler(),
+	)
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(apperrors.NotFound("Route not found"))
+	})
+
+	router.GET("/healthz", controllers.HealthCheckHandler)
+	router.GET("/readyz", opts.Health.Readiness)
+	// Kept for probes configured before /healthz existed
 	router.GET("/healthcheck", controllers.HealthCheckHandler)
-	router.POST("/register", controllers.Register)
-	router.POST("/login", controllers.Login)
+	router.GET("/.well-known/jwks.json", tokenController.JWKS)
+
+	authRoutes := router.Group("/")
+	authRoutes.Use(middlewares.RateLimit(limits, RateLimitAuth, opts.RateLimits[RateLimitAuth], middlewares.ByIP, middlewares.ByUsername))
+	{
+		authRoutes.POST("/register", userController.Register)
+		authRoutes.POST("/login", userController.Login)
+		authRoutes.POST("/token/refresh", tokenController.Refresh)
+	}
 
 	protectedRoutes := router.Group("/")
-	protectedRoutes.Use(middlewares.AuthMiddleware())
+	protectedRoutes.Use(
+		middlewares.AuthMiddleware(keys, repos.Denylist, repos.Users),
+		middlewares.RateLimit(limits, RateLimitAPI, opts.RateLimits[RateLimitAPI], middlewares.ByIP, middlewares.ByUsername),
+	)
+	{
+		protectedRoutes.POST("/logout", tokenController.Logout)
+		protectedRoutes.POST("/posts", postController.CreatePost)
+		protectedRoutes.GET("/posts", postController.GetPosts)
+		protectedRoutes.GET("/posts/:id", postController.GetPost)
+		protectedRoutes.PUT("/posts/:id", postController.UpdatePost)
+		protectedRoutes.PATCH("/posts/:id", postController.PatchPost)
+		protectedRoutes.DELETE("/posts/:id", postController.DeletePost)
+		protectedRoutes.POST("/posts/:id/restore", postController.RestorePost)
+		protectedRoutes.GET("/me/trash", postController.GetTrash)
+		protectedRoutes.GET("/posts/:id/revisions", postController.GetRevisions)
+		protectedRoutes.GET("/posts/:id/revisions/:number", postController.GetRevision)
+		protectedRoutes.POST("/posts/:id/revisions/:number/restore", postController.RestoreRevision)
+
+		protectedRoutes.POST("/posts/:id/comments", commentController.CreateComment)
+		protectedRoutes.GET("/posts/:id/comments", commentController.GetComments)
+		protectedRoutes.PUT("/posts/:id/comments/:commentId", commentController.UpdateComment)
+		protectedRoutes.DELETE("/posts/:id/comments/:commentId", commentController.DeleteComment)
+
+		protectedRoutes.POST("/posts/:id/reactions", reactionController.React)
+		protectedRoutes.DELETE("/posts/:id/reactions", reactionController.Unreact)
+
+		protectedRoutes.GET("/users/:username", followController.GetProfile)
+		protectedRoutes.POST("/users/:username/follow", followController.Follow)
+		protectedRoutes.DELETE("/users/:username/follow", followController.Unfollow)
+		protectedRoutes.GET("/users/:username/followers", followController.GetFollowers)
+		protectedRoutes.GET("/users/:username/following", followController.GetFollowing)
+
+		protectedRoutes.GET("/timeline/home", timelineController.GetHomeTimeline)
+	}
+
+	moderatorRoutes := protectedRoutes.Group("/admin")
+	moderatorRoutes.Use(middlewares.RequireRole(models.RoleModerator, models.RoleAdmin))
+	{
+		moderatorRoutes.POST("/users/:username/suspension", adminController.SuspendUser)
+		moderatorRoutes.DELETE("/users/:username/suspension", adminController.UnsuspendUser)
+		moderatorRoutes.DELETE("/posts/:id", adminController.DeletePost)
+	}
+
+	adminRoutes := protectedRoutes.Group("/admin")
+	adminRoutes.Use(middlewares.RequireRole(models.RoleAdmin))
 	{
-		protectedRoutes.POST("/posts", controllers.CreatePost)
-		protectedRoutes.GET("/posts", controllers.GetPosts)
-		protectedRoutes.GET("/posts/:id", controllers.GetPost)
-		protectedRoutes.PUT("/posts/:id", controllers.UpdatePost)
-		protectedRoutes.DELETE("/posts/:id", controllers.DeletePost)
+		adminRoutes.GET("/users", adminController.ListUsers)
+		adminRoutes.PUT("/users/:username/role", adminController.SetRole)
 	}
 }
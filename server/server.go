@@ -0,0 +1,68 @@
+// Package server runs the HTTP server and shuts it down gracefully
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/config"
+)
+
+// New builds the server for handler with the address, timeouts and header limit of cfg
+func New(cfg config.Configuration, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:           cfg.ServerAddr,
+		Handler:        handler,
+		ReadTimeout:    time.Duration(cfg.ReadTimeout),
+		WriteTimeout:   time.Duration(cfg.WriteTimeout),
+		IdleTimeout:    time.Duration(cfg.IdleTimeout),
+		MaxHeaderBytes: cfg.MaxHeaderBytes,
+	}
+}
+
+// ListenAndServe listens on srv.Addr and serves until ctx is done, see Serve
+func ListenAndServe(ctx context.Context, srv *http.Server, cfg config.Configuration, cleanup ...func(context.Context) error) error {
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, srv, listener, cfg, cleanup...)
+}
+
+// Serve accepts connections on listener, over TLS when cfg names a certificate,
+// until ctx is done. It then stops accepting, waits for in-flight requests and
+// runs cleanup, such as disconnecting the database, all within cfg.ShutdownTimeout.
+func Serve(ctx context.Context, srv *http.Server, listener net.Listener, cfg config.Configuration, cleanup ...func(context.Context) error) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if cfg.TLSCertFile != "" {
+			serveErr <- srv.ServeTLS(listener, cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			serveErr <- srv.Serve(listener)
+		}
+	}()
+	slog.Info("Listening", "addr", listener.Addr().String())
+
+	select {
+	case err := <-serveErr:
+		// The server stopped on its own, so there is nothing to drain
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
+	errs := []error{srv.Shutdown(shutdownCtx)}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+	for _, fn := range cleanup {
+		errs = append(errs, fn(shutdownCtx))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeDrainsRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := config.Configuration{ShutdownTimeout: config.Duration(5 * time.Second)}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cleanedUp := false
+	done := make(chan error)
+	go func() {
+		done <- Serve(ctx, New(cfg, handler), listener, cfg, func(context.Context) error {
+			cleanedUp = true
+			return nil
+		})
+	}()
+
+	response := make(chan int)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			response <- 0
+			return
+		}
+		resp.Body.Close()
+		response <- resp.StatusCode
+	}()
+	<-started
+	cancel()
+
+	// The in-flight request holds up the shutdown until it finishes
+	select {
+	case <-done:
+		t.Fatal("Serve returned before the in-flight request finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	assert.Equal(t, http.StatusOK, <-response)
+	assert.NoError(t, <-done)
+	assert.True(t, cleanedUp)
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	cfg := config.Configuration{ShutdownTimeout: config.Duration(50 * time.Millisecond)}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Serve(ctx, New(cfg, handler), listener, cfg)
+	}()
+	go http.Get("http://" + listener.Addr().String())
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, <-done, context.DeadlineExceeded)
+}
@@ -0,0 +1,42 @@
+package timeline
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fanOutOnRead struct {
+	posts   repositories.PostRepository
+	follows repositories.FollowRepository
+}
+
+// NewFanOutOnRead merges the posts of followed accounts when the timeline is read.
+// Writes cost nothing, but reads get slower the more accounts a user follows.
+func NewFanOutOnRead(posts repositories.PostRepository, follows repositories.FollowRepository) Strategy {
+	return &fanOutOnRead{posts: posts, follows: follows}
+}
+
+func (s *fanOutOnRead) Home(ctx context.Context, userID primitive.ObjectID, page repositories.Page) ([]models.Post, error) {
+	authorIDs, err := s.follows.FollowingIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	authorIDs = append(authorIDs, userID)
+	return s.posts.ListByAuthors(ctx, authorIDs, page)
+}
+
+func (s *fanOutOnRead) PostCreated(ctx context.Context, post models.Post) error {
+	return nil
+}
+
+func (s *fanOutOnRead) PostDeleted(ctx context.Context, post models.Post) error {
+	return nil
+}
+
+func (s *fanOutOnRead) Unfollowed(ctx context.Context, followerID primitive.ObjectID, followeeID primitive.ObjectID) error {
+	return nil
+}
@@ -0,0 +1,41 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Names of the strategies, as used by config.Configuration.TimelineStrategy
+const (
+	FanOutOnRead  = "fanout_on_read"
+	FanOutOnWrite = "fanout_on_write"
+)
+
+// Strategy builds home timelines: the posts of the accounts a user follows plus their own
+type Strategy interface {
+	// Home returns up to page.Limit posts of userID's home timeline, newest first
+	Home(ctx context.Context, userID primitive.ObjectID, page repositories.Page) ([]models.Post, error)
+	// PostCreated is called once post has been stored or restored from the trash
+	PostCreated(ctx context.Context, post models.Post) error
+	// PostDeleted is called once post has been moved to the trash or removed
+	PostDeleted(ctx context.Context, post models.Post) error
+	// Unfollowed is called once followerID has stopped following followeeID
+	Unfollowed(ctx context.Context, followerID primitive.ObjectID, followeeID primitive.ObjectID) error
+}
+
+// New returns the strategy called name, defaulting to fan-out-on-read
+func New(name string, repos *repositories.Repositories) (Strategy, error) {
+	switch name {
+	case "", FanOutOnRead:
+		return NewFanOutOnRead(repos.Posts, repos.Follows), nil
+	case FanOutOnWrite:
+		return NewFanOutOnWrite(repos.Posts, repos.Follows, repos.Timelines), nil
+	default:
+		return nil, fmt.Errorf("unknown timeline strategy %q", name)
+	}
+}
@@ -0,0 +1,116 @@
+package timeline
+
+import (
+	"context"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fanOutBatchSize caps the entries written per round trip when a post is fanned out
+const fanOutBatchSize = 1000
+
+type fanOutOnWrite struct {
+	posts     repositories.PostRepository
+	follows   repositories.FollowRepository
+	timelines repositories.TimelineRepository
+}
+
+// NewFanOutOnWrite copies every new post onto the stored timeline of each follower
+// of its author, so reading a timeline is a single indexed range no matter how many
+// accounts the reader follows. Posts written before a follow are not backfilled.
+func NewFanOutOnWrite(posts repositories.PostRepository, follows repositories.FollowRepository, timelines repositories.TimelineRepository) Strategy {
+	return &fanOutOnWrite{posts: posts, follows: follows, timelines: timelines}
+}
+
+func (s *fanOutOnWrite) Home(ctx context.Context, userID primitive.ObjectID, page repositories.Page) ([]models.Post, error) {
+	posts := []models.Post{}
+	// Entries of posts deleted since they were fanned out are skipped, so read
+	// on past them until the page is full or the timeline runs out
+	next := page
+	for len(posts) < page.Limit {
+		next.Limit = page.Limit - len(posts)
+		entries, err := s.timelines.List(ctx, userID, next)
+		if err != nil {
+			return nil, err
+		}
+		found, err := s.entryPosts(ctx, entries)
+		if err != nil {
+			return nil, err
+		}
+		if page.Prev {
+			posts = append(found, posts...)
+		} else {
+			posts = append(posts, found...)
+		}
+		if len(entries) < next.Limit {
+			break
+		}
+		// Walking backwards the next entries are newer than the newest read
+		if page.Prev {
+			next.Cursor = entries[0].PostID
+		} else {
+			next.Cursor = entries[len(entries)-1].PostID
+		}
+	}
+	return posts, nil
+}
+
+// entryPosts returns the posts of entries in their order, leaving out the
+// posts that no longer exist
+func (s *fanOutOnWrite) entryPosts(ctx context.Context, entries []models.TimelineEntry) ([]models.Post, error) {
+	ids := make([]primitive.ObjectID, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.PostID)
+	}
+	found, err := s.posts.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]models.Post, len(found))
+	for _, post := range found {
+		byID[post.ID] = post
+	}
+	posts := make([]models.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := byID[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (s *fanOutOnWrite) PostCreated(ctx context.Context, post models.Post) error {
+	followerIDs, err := s.follows.FollowerIDs(ctx, post.AuthorID)
+	if err != nil {
+		return err
+	}
+	userIDs := append(followerIDs, post.AuthorID)
+
+	for start := 0; start < len(userIDs); start += fanOutBatchSize {
+		batch := userIDs[start:min(start+fanOutBatchSize, len(userIDs))]
+		entries := make([]models.TimelineEntry, 0, len(batch))
+		for _, userID := range batch {
+			entries = append(entries, models.TimelineEntry{
+				ID:       primitive.NewObjectID(),
+				UserID:   userID,
+				PostID:   post.ID,
+				AuthorID: post.AuthorID,
+			})
+		}
+		if err := s.timelines.Add(ctx, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fanOutOnWrite) PostDeleted(ctx context.Context, post models.Post) error {
+	return s.timelines.RemovePost(ctx, post.ID)
+}
+
+func (s *fanOutOnWrite) Unfollowed(ctx context.Context, followerID primitive.ObjectID, followeeID primitive.ObjectID) error {
+	return s.timelines.RemoveAuthor(ctx, followerID, followeeID)
+}
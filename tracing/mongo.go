@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MongoMonitor returns a command monitor that records each MongoDB command as
+// a child span of the span in the context the command was run with. Pass it to
+// options.ClientOptions.SetMonitor.
+func MongoMonitor() *event.CommandMonitor {
+	var spans sync.Map
+	finished := func(e event.CommandFinishedEvent, err string) {
+		value, ok := spans.LoadAndDelete(e.RequestID)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if err != "" {
+			span.SetStatus(codes.Error, err)
+		}
+		span.End()
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			collection, _ := e.Command.Lookup(e.CommandName).StringValueOK()
+			name := e.CommandName
+			if collection != "" {
+				name = collection + "." + e.CommandName
+			}
+			_, span := Tracer().Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBNamespace(e.DatabaseName),
+					semconv.DBOperationName(e.CommandName),
+					semconv.DBCollectionName(collection),
+				),
+			)
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finished(e.CommandFinishedEvent, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finished(e.CommandFinishedEvent, e.Failure)
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMongoMonitor(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	monitor := MongoMonitor()
+
+	ctx, parent := Tracer().Start(context.Background(), "GET /posts/:id")
+	command, _ := bson.Marshal(bson.D{{Key: "find", Value: "posts"}})
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command:      command,
+		DatabaseName: "social_media",
+		CommandName:  "find",
+		RequestID:    1,
+	})
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command:      command,
+		DatabaseName: "social_media",
+		CommandName:  "find",
+		RequestID:    2,
+	})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 2}, Failure: "timed out"})
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 3) {
+		for _, span := range spans[:2] {
+			assert.Equal(t, "posts.find", span.Name)
+			assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+			// Commands are children of the span of the context they ran with
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+			assert.Contains(t, span.Attributes, attribute.String("db.collection.name", "posts"))
+		}
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+		assert.Equal(t, codes.Error, spans[1].Status.Code)
+		assert.Equal(t, "timed out", spans[1].Status.Description)
+	}
+}
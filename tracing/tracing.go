@@ -0,0 +1,84 @@
+// Package tracing sets up OpenTelemetry tracing: the exporter, the W3C trace
+// context propagation and the spans of MongoDB commands.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters name where spans are sent
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// ServiceName identifies the API in traces
+const ServiceName = "social-media-api"
+
+// instrumentation names the tracer of the spans started here
+const instrumentation = "github.com/VisarutJDev/social-media-api"
+
+// Tracer starts the spans of the API
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Setup installs the global tracer provider exporting to exporter and the W3C
+// traceparent and baggage propagators. endpoint is the OTLP/HTTP collector,
+// such as collector:4318, reached over TLS unless it starts with http://;
+// when empty the OTEL_EXPORTER_OTLP_* variables apply.
+// The returned function shuts the provider down, flushing the buffered spans.
+func Setup(ctx context.Context, exporter string, endpoint string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterNone, "":
+		// The propagator still forwards incoming trace context
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx, endpointOptions(endpoint)...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// endpointOptions point the OTLP exporter at endpoint: over plain HTTP when
+// it starts with http://, and over TLS when it starts with https:// or has
+// no scheme
+func endpointOptions(endpoint string) []otlptracehttp.Option {
+	if endpoint == "" {
+		return nil
+	}
+	if host, ok := strings.CutPrefix(endpoint, "http://"); ok {
+		return []otlptracehttp.Option{otlptracehttp.WithEndpoint(strings.TrimSuffix(host, "/")), otlptracehttp.WithInsecure()}
+	}
+	host := strings.TrimPrefix(endpoint, "https://")
+	return []otlptracehttp.Option{otlptracehttp.WithEndpoint(strings.TrimSuffix(host, "/"))}
+}
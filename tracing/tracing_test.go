@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exportSpan sets up the OTLP exporter for endpoint and flushes one span to it
+func exportSpan(t *testing.T, endpoint string) {
+	shutdown, err := Setup(context.Background(), ExporterOTLP, endpoint)
+	assert.NoError(t, err)
+	_, span := Tracer().Start(context.Background(), "GET /posts")
+	span.End()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	shutdown(ctx)
+}
+
+func TestSetupPlainHTTP(t *testing.T) {
+	var exported atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exported.Add(1)
+		}
+	}))
+	defer server.Close()
+
+	exportSpan(t, server.URL)
+	assert.Equal(t, int32(1), exported.Load())
+}
+
+func TestSetupTLS(t *testing.T) {
+	var hellos atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.TLS = &tls.Config{GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		hellos.Add(1)
+		return nil, nil
+	}}
+	server.StartTLS()
+	defer server.Close()
+
+	// The test certificate is not trusted, but the exporter must at least try TLS
+	host := strings.TrimPrefix(server.URL, "https://")
+	for _, endpoint := range []string{server.URL, host} {
+		hellos.Store(0)
+		exportSpan(t, endpoint)
+		assert.NotZero(t, hellos.Load(), endpoint)
+	}
+}
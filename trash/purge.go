@@ -0,0 +1,130 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/metrics"
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+	"github.com/VisarutJDev/social-media-api/timeline"
+)
+
+// purgeBatchSize caps the posts read from the trash per round trip
+const purgeBatchSize = 100
+
+// Purger permanently removes posts left in the trash longer than Retention,
+// together with their comments, reactions, revisions and timeline entries
+type Purger struct {
+	Posts     repositories.PostRepository
+	Comments  repositories.CommentRepository
+	Reactions repositories.ReactionRepository
+	Revisions repositories.RevisionRepository
+	Timeline  timeline.Strategy
+	Retention time.Duration
+}
+
+func NewPurger(repos *repositories.Repositories, strategy timeline.Strategy, retention time.Duration) *Purger {
+	return &Purger{
+		Posts:     repos.Posts,
+		Comments:  repos.Comments,
+		Reactions: repos.Reactions,
+		Revisions: repos.Revisions,
+		Timeline:  strategy,
+		Retention: retention,
+	}
+}
+
+// Run purges once every interval until ctx is done. Failures are logged and
+// retried on the next run.
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := p.Purge(ctx, models.Now())
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to purge the trash", "purged", purged, "error", err)
+		} else if purged > 0 {
+			slog.InfoContext(ctx, "Purged the trash", "purged", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Start runs Run in the background. The returned function waits until Run
+// has returned after ctx is done, or until its own ctx is, so that the
+// database is not disconnected under a purge.
+func (p *Purger) Start(ctx context.Context, interval time.Duration) func(context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run(ctx, interval)
+	}()
+	return func(wait context.Context) error {
+		select {
+		case <-done:
+			return nil
+		case <-wait.Done():
+			return wait.Err()
+		}
+	}
+}
+
+// Purge removes the posts moved to the trash before now minus Retention and
+// returns how many it removed. Once ctx is done it stops before the next post,
+// but a post it has started on is removed with everything attached.
+func (p *Purger) Purge(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.Add(-p.Retention)
+	purged := 0
+	for {
+		posts, err := p.Posts.ListDeletedBefore(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		for _, post := range posts {
+			if err := ctx.Err(); err != nil {
+				return purged, err
+			}
+			if err := p.remove(context.WithoutCancel(ctx), post); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if len(posts) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// remove deletes post for good. Only failing to delete the post itself stops
+// the purge; what it leaves behind of the rest is logged.
+func (p *Purger) remove(ctx context.Context, post models.Post) error {
+	err := p.Posts.Delete(ctx, post.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		// Another instance purged it first
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	metrics.PostsPurged.Inc()
+	if err := p.Comments.DeleteByPost(ctx, post.ID); err != nil {
+		slog.ErrorContext(ctx, "Failed to delete comments of post", "post_id", post.ID.Hex(), "error", err)
+	}
+	if err := p.Reactions.DeleteByPost(ctx, post.ID); err != nil {
+		slog.ErrorContext(ctx, "Failed to delete reactions to post", "post_id", post.ID.Hex(), "error", err)
+	}
+	if err := p.Revisions.DeleteByPost(ctx, post.ID); err != nil {
+		slog.ErrorContext(ctx, "Failed to delete revisions of post", "post_id", post.ID.Hex(), "error", err)
+	}
+	if err := p.Timeline.PostDeleted(ctx, post); err != nil {
+		slog.ErrorContext(ctx, "Failed to remove post from timelines", "post_id", post.ID.Hex(), "error", err)
+	}
+	return nil
+}
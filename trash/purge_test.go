@@ -0,0 +1,129 @@
+package trash
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VisarutJDev/social-media-api/models"
+	"github.com/VisarutJDev/social-media-api/repositories"
+	"github.com/VisarutJDev/social-media-api/timeline"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// insertPost stores a post of authorID with a comment on it, moved to the trash
+// at deletedAt unless it is zero
+func insertPost(repos *repositories.Repositories, authorID primitive.ObjectID, deletedAt time.Time) (models.Post, models.Comment) {
+	ctx := context.Background()
+	post := models.Post{ID: primitive.NewObjectID(), Title: "Post", Content: "Content", AuthorID: authorID}
+	repos.Posts.Create(ctx, post)
+	comment := models.Comment{ID: primitive.NewObjectID(), PostID: post.ID, AuthorID: authorID, Content: "Comment"}
+	repos.Comments.Create(ctx, comment)
+	if !deletedAt.IsZero() {
+		repos.Posts.SoftDelete(ctx, post.ID, 0, "alice", deletedAt)
+	}
+	return post, comment
+}
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	repos := repositories.NewMemoryRepositories()
+	purger := NewPurger(repos, timeline.NewFanOutOnRead(repos.Posts, repos.Follows), 24*time.Hour)
+	now := models.Now()
+	authorID := primitive.NewObjectID()
+
+	expired, expiredComment := insertPost(repos, authorID, now.Add(-25*time.Hour))
+	recent, recentComment := insertPost(repos, authorID, now.Add(-time.Hour))
+	live, _ := insertPost(repos, authorID, time.Time{})
+
+	purged, err := purger.Purge(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = repos.Posts.FindDeleted(ctx, expired.ID)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+	_, err = repos.Comments.FindByID(ctx, expiredComment.ID)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	_, err = repos.Posts.FindDeleted(ctx, recent.ID)
+	assert.NoError(t, err)
+	_, err = repos.Comments.FindByID(ctx, recentComment.ID)
+	assert.NoError(t, err)
+	_, err = repos.Posts.FindByID(ctx, live.ID)
+	assert.NoError(t, err)
+
+	// Nothing is left to purge until the recent post expires too
+	purged, err = purger.Purge(ctx, now)
+	assert.NoError(t, err)
+	assert.Zero(t, purged)
+	purged, err = purger.Purge(ctx, now.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+}
+
+// cancelOnDelete cancels the purge once a post has been deleted, as a
+// shutdown arriving mid purge would
+type cancelOnDelete struct {
+	repositories.PostRepository
+	cancel context.CancelFunc
+}
+
+func (r cancelOnDelete) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.cancel()
+	return r.PostRepository.Delete(ctx, id)
+}
+
+// cancellableComments fails like MongoDB once ctx is done
+type cancellableComments struct {
+	repositories.CommentRepository
+}
+
+func (r cancellableComments) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.CommentRepository.DeleteByPost(ctx, postID)
+}
+
+func TestPurgeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repos := repositories.NewMemoryRepositories()
+	purger := NewPurger(repos, timeline.NewFanOutOnRead(repos.Posts, repos.Follows), 24*time.Hour)
+	purger.Posts = cancelOnDelete{repos.Posts, cancel}
+	purger.Comments = cancellableComments{repos.Comments}
+	now := models.Now()
+	authorID := primitive.NewObjectID()
+	first, firstComment := insertPost(repos, authorID, now.Add(-26*time.Hour))
+	second, secondComment := insertPost(repos, authorID, now.Add(-25*time.Hour))
+
+	// The post being removed goes with everything attached; the next one is left for later
+	purged, err := purger.Purge(ctx, now)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, purged)
+	_, err = repos.Posts.FindDeleted(context.Background(), first.ID)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+	_, err = repos.Comments.FindByID(context.Background(), firstComment.ID)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+	_, err = repos.Posts.FindDeleted(context.Background(), second.ID)
+	assert.NoError(t, err)
+	_, err = repos.Comments.FindByID(context.Background(), secondComment.ID)
+	assert.NoError(t, err)
+}
+
+func TestPurgerStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repos := repositories.NewMemoryRepositories()
+	purger := NewPurger(repos, timeline.NewFanOutOnRead(repos.Posts, repos.Follows), 24*time.Hour)
+	wait := purger.Start(ctx, time.Hour)
+
+	// Waiting gives up with its own context while the purger runs on
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	assert.ErrorIs(t, wait(short), context.DeadlineExceeded)
+
+	// and returns once the purger has stopped
+	cancel()
+	assert.NoError(t, wait(context.Background()))
+}